@@ -0,0 +1,42 @@
+package speedtest_test
+
+import (
+	"testing"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregationOptions(t *testing.T) {
+	rates := []speedtest.BitRate{speedtest.Mbps(10), speedtest.Mbps(20), speedtest.Mbps(90)}
+
+	// caller outside of module can hold aggregations
+	// in variables and define its own ones
+	aggregations := map[string]speedtest.Aggregation{
+		"median": speedtest.Median,
+		"p50":    speedtest.Percentile(50),
+		"lowest": func(rates []speedtest.BitRate) speedtest.BitRate {
+			lowest := rates[0]
+			for _, rate := range rates[1:] {
+				if rate < lowest {
+					lowest = rate
+				}
+			}
+			return lowest
+		},
+	}
+	expectedRates := map[string]speedtest.BitRate{
+		"median": speedtest.Mbps(20),
+		"p50":    speedtest.Mbps(20),
+		"lowest": speedtest.Mbps(10),
+	}
+
+	for name, aggregation := range aggregations {
+		conf := &speedtest.Config{}
+		speedtest.WithServerAggregation(aggregation)(conf)
+
+		rate, spread := conf.AggregateServers(rates)
+		assert.Equal(t, expectedRates[name], rate, name)
+		assert.Equal(t, speedtest.Mbps(90), spread.Max, name)
+	}
+}
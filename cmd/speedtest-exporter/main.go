@@ -0,0 +1,100 @@
+// Command speedtest-exporter exposes download/upload speeds and
+// latency measured by Ookla's speedtest.net, Netflix's fast.com,
+// LibreSpeed or Cloudflare's speed.cloudflare.com as Prometheus metrics
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/exporter"
+	"github.com/bejaneps/speedtest/internal/config"
+)
+
+type flags struct {
+	tool        string
+	listen      string
+	serverCount int
+	token       string
+	interval    time.Duration
+	timeout     time.Duration
+}
+
+func main() {
+	f := flags{}
+	flag.StringVar(&f.tool, "tool", "ookla", "measurement tool: ookla, netflix, librespeed or cloudflare")
+	flag.StringVar(&f.listen, "listen", ":9516", "address to serve metrics on")
+	flag.IntVar(&f.serverCount, "servers", 1, "amount of servers used for measurement")
+	flag.StringVar(&f.token, "token", "", "token for fast.com api, fetched automatically if empty")
+	flag.DurationVar(&f.interval, "interval", 0, "interval between measurements, measures on every scrape if zero")
+	flag.DurationVar(&f.timeout, "timeout", 2*time.Minute, "timeout of a single measurement")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, f); err != nil {
+		fmt.Fprintf(os.Stderr, "speedtest-exporter: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, f flags) error {
+	if f.serverCount < 1 {
+		return errors.New("amount of servers should be positive")
+	}
+
+	tool := speedtest.Tool(f.tool)
+
+	opts := []config.Option{speedtest.WithServerCount(f.serverCount)}
+	if f.token != "" {
+		opts = append(opts, speedtest.WithToken(f.token))
+	}
+
+	measurer, err := speedtest.New(tool, opts...)
+	if err != nil {
+		return err
+	}
+
+	e := exporter.New(
+		tool.String(),
+		measurer,
+		exporter.WithInterval(f.interval),
+		exporter.WithTimeout(f.timeout),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+
+	srv := &http.Server{
+		Addr:              f.listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			fmt.Printf("failed to shutdown server: %v\n", err)
+		}
+	}()
+
+	go func() {
+		if err := e.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Printf("failed to run exporter: %v\n", err)
+		}
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Command speedtest-server serves endpoints of Ookla's speedtest.net
+// servers, so speed can be measured against self-hosted servers with
+// `speedtest -tool ookla -server-list http://host:8080/api/js/servers`
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bejaneps/speedtest/server"
+)
+
+type flags struct {
+	listen  string
+	name    string
+	sponsor string
+	country string
+}
+
+func main() {
+	f := flags{}
+	flag.StringVar(&f.listen, "listen", ":8080", "address to serve on")
+	flag.StringVar(&f.name, "name", "self-hosted", "name of server in server list")
+	flag.StringVar(&f.sponsor, "sponsor", "", "sponsor of server in server list")
+	flag.StringVar(&f.country, "country", "", "country of server in server list")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, f); err != nil {
+		fmt.Fprintf(os.Stderr, "speedtest-server: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, f flags) error {
+	srv := &http.Server{
+		Addr:              f.listen,
+		Handler:           server.New(server.WithName(f.name, f.sponsor, f.country)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			fmt.Printf("failed to shutdown server: %v\n", err)
+		}
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	return nil
+}
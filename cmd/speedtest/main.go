@@ -0,0 +1,185 @@
+// Command speedtest measures download/upload speeds and latency
+// using Ookla's speedtest.net, Netflix's fast.com, LibreSpeed
+// or Cloudflare's speed.cloudflare.com
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+type flags struct {
+	tool        string
+	serverCount int
+	token       string
+	phases      string
+	format      string
+	duration    time.Duration
+	aggregation string
+	proxy       string
+	sourceIP    string
+	iface       string
+	serverList  string
+	baseURL     string
+	ipVersion   int
+	timeout     time.Duration
+	listServers bool
+}
+
+func main() {
+	f := flags{}
+	flag.StringVar(&f.tool, "tool", "ookla", "measurement tool: ookla, netflix, librespeed or cloudflare")
+	flag.IntVar(&f.serverCount, "servers", 1, "amount of servers used for measurement")
+	flag.StringVar(&f.token, "token", "", "token for fast.com api, fetched automatically if empty")
+	flag.StringVar(&f.phases, "phases", "latency,download,upload", "comma separated phases to run")
+	flag.StringVar(&f.format, "format", "human", "output format: human, json or csv")
+	flag.DurationVar(&f.duration, "duration", 0, "duration of download test against each server, not supported by netflix")
+	flag.StringVar(&f.aggregation, "aggregation", "mean", "aggregation of server rates: mean, median, max or percentile, e.g. p90")
+	flag.StringVar(&f.proxy, "proxy", "", "proxy url to send requests through")
+	flag.StringVar(&f.sourceIP, "source", "", "source ip to bind connections to")
+	flag.StringVar(&f.iface, "interface", "", "network interface to bind connections to")
+	flag.StringVar(&f.serverList, "server-list", "", "url of server list, e.g. of self-hosted server, ookla and librespeed only")
+	flag.StringVar(&f.baseURL, "base-url", "", "base url of speed test endpoints, e.g. of local stand-in, cloudflare only")
+	flag.IntVar(&f.ipVersion, "ip", 0, "ip version to measure: 4 or 6, picked automatically if zero")
+	flag.DurationVar(&f.timeout, "timeout", 0, "timeout of each measurement phase, unlimited if zero")
+	flag.BoolVar(&f.listServers, "list-servers", false, "list servers used for measurement and exit")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, f); err != nil {
+		fmt.Fprintf(os.Stderr, "speedtest: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, f flags) error {
+	opts, err := f.options()
+	if err != nil {
+		return err
+	}
+
+	tool := speedtest.Tool(f.tool)
+
+	if f.listServers {
+		measurer, err := speedtest.New(tool, opts...)
+		if err != nil {
+			return err
+		}
+
+		lister, ok := measurer.(speedtest.ServerLister)
+		if !ok {
+			return fmt.Errorf("tool %q doesn't support listing servers", f.tool)
+		}
+
+		servers, err := lister.Servers(ctx)
+		if err != nil {
+			return err
+		}
+
+		return writeServers(os.Stdout, f.format, servers)
+	}
+
+	result, err := speedtest.Run(ctx, tool, opts...)
+	if err != nil {
+		return err
+	}
+
+	return writeResult(os.Stdout, f.format, result)
+}
+
+// options converts flags to speedtest options
+func (f flags) options() ([]config.Option, error) {
+	if f.serverCount < 1 {
+		return nil, errors.New("amount of servers should be positive")
+	}
+
+	switch f.format {
+	case formatHuman, formatJSON, formatCSV:
+	default:
+		return nil, fmt.Errorf("unknown format %q", f.format)
+	}
+
+	var phases []measurement.Phase
+	for _, phase := range strings.Split(f.phases, ",") {
+		switch p := measurement.Phase(strings.TrimSpace(phase)); p {
+		case speedtest.PhaseLatency, speedtest.PhaseDownload, speedtest.PhaseUpload:
+			phases = append(phases, p)
+		default:
+			return nil, fmt.Errorf("unknown phase %q", phase)
+		}
+	}
+
+	aggregation, err := parseAggregation(f.aggregation)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []config.Option{
+		speedtest.WithServerCount(f.serverCount),
+		speedtest.WithPhases(phases...),
+		speedtest.WithServerAggregation(aggregation),
+	}
+	if f.token != "" {
+		opts = append(opts, speedtest.WithToken(f.token))
+	}
+	if f.duration > 0 {
+		opts = append(opts, speedtest.WithDuration(f.duration))
+	}
+	if f.proxy != "" {
+		opts = append(opts, speedtest.WithProxy(f.proxy))
+	}
+	if f.sourceIP != "" {
+		opts = append(opts, speedtest.WithSourceIP(f.sourceIP))
+	}
+	if f.iface != "" {
+		opts = append(opts, speedtest.WithInterface(f.iface))
+	}
+	if f.serverList != "" {
+		opts = append(opts, speedtest.WithServerListURL(f.serverList))
+	}
+	if f.baseURL != "" {
+		opts = append(opts, speedtest.WithBaseURL(f.baseURL))
+	}
+	if f.ipVersion != 0 {
+		opts = append(opts, speedtest.WithIPVersion(f.ipVersion))
+	}
+	if f.timeout > 0 {
+		opts = append(opts, speedtest.WithTimeout(f.timeout))
+	}
+
+	return opts, nil
+}
+
+// parseAggregation parses name of aggregation
+func parseAggregation(name string) (measurement.Aggregation, error) {
+	switch name {
+	case "", "mean":
+		return speedtest.Mean, nil
+	case "median":
+		return speedtest.Median, nil
+	case "max":
+		return speedtest.Maximum, nil
+	}
+
+	if strings.HasPrefix(name, "p") {
+		p, err := strconv.ParseFloat(name[1:], 64)
+		if err == nil && p >= 0 && p <= 100 {
+			return speedtest.Percentile(p), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown aggregation %q", name)
+}
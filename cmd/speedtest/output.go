@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+const (
+	formatHuman = "human"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+var serverHeader = []string{
+	"id", "name", "sponsor", "country", "host", "url", "distance", "latency_ms",
+}
+
+// writeResult writes result of measurement in provided format
+func writeResult(w io.Writer, format string, result *speedtest.Result) error {
+	switch format {
+	case formatJSON:
+		return json.NewEncoder(w).Encode(result)
+	case formatCSV:
+		return speedtest.NewCSVWriter(w, false).Write(result)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Tool:\t%s\n", result.Tool)
+	for _, server := range result.Servers {
+		fmt.Fprintf(tw, "Server:\t%s\n", serverName(server))
+	}
+	if result.ISP != "" {
+		client := fmt.Sprintf("%s (%s)", result.ClientIP, result.ISP)
+		if result.IPVersion != 0 {
+			client = fmt.Sprintf("%s (%s, IPv%d)", result.ClientIP, result.ISP, result.IPVersion)
+		}
+		fmt.Fprintf(tw, "Client:\t%s\n", client)
+	}
+	// skipped phases are omitted
+	if result.Ran(speedtest.PhaseLatency) {
+		fmt.Fprintf(tw, "Latency:\t%s (jitter %s)\n", result.Latency, result.Jitter)
+	}
+	if result.Ran(speedtest.PhaseDownload) {
+		fmt.Fprintf(tw, "Download:\t%s\n", rateStr(result.Download, result.DownloadSpread))
+	}
+	if result.Ran(speedtest.PhaseUpload) {
+		fmt.Fprintf(tw, "Upload:\t%s\n", rateStr(result.Upload, result.UploadSpread))
+	}
+	fmt.Fprintf(tw, "Duration:\t%s\n", result.Duration.Round(time.Millisecond))
+
+	return tw.Flush()
+}
+
+// writeServers writes list of servers in provided format
+func writeServers(w io.Writer, format string, servers []measurement.Server) error {
+	switch format {
+	case formatJSON:
+		return json.NewEncoder(w).Encode(servers)
+	case formatCSV:
+		rows := make([][]string, 0, len(servers))
+		for _, server := range servers {
+			rows = append(rows, []string{
+				server.ID,
+				server.Name,
+				server.Sponsor,
+				server.Country,
+				server.Host,
+				server.URL,
+				strconv.FormatFloat(server.Distance, 'f', -1, 64),
+				strconv.FormatFloat(float64(server.Latency)/float64(time.Millisecond), 'f', 3, 64),
+			})
+		}
+		return writeCSV(w, serverHeader, rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, server := range servers {
+		if server.Latency > 0 {
+			fmt.Fprintf(tw, "%s\t%s\n", serverName(server), server.Latency)
+		} else {
+			fmt.Fprintf(tw, "%s\t\n", serverName(server))
+		}
+	}
+
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+// rateStr returns human readable rate, with its
+// spread if it was aggregated from different rates
+func rateStr(rate measurement.BitRate, spread measurement.Spread) string {
+	if spread.Min == spread.Max {
+		return rate.HumanStr()
+	}
+
+	return fmt.Sprintf(
+		"%s (min %s, max %s, stddev %s)",
+		rate.HumanStr(),
+		spread.Min.HumanStr(),
+		spread.Max.HumanStr(),
+		spread.StdDev.HumanStr(),
+	)
+}
+
+// serverName returns human readable name of server
+func serverName(server measurement.Server) string {
+	if server.Name == "" {
+		return server.URL
+	}
+
+	return fmt.Sprintf("%s, %s (%s)", server.Name, server.Country, server.Sponsor)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteResult(t *testing.T) {
+	result := &speedtest.Result{
+		Tool:          "ookla",
+		Timestamp:     time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC),
+		Duration:      10 * time.Second,
+		Download:      94_200_000,
+		Upload:        10_500_000,
+		Latency:       12 * time.Millisecond,
+		Jitter:        1500 * time.Microsecond,
+		BytesReceived: 1000,
+		BytesSent:     500,
+		Servers: []measurement.Server{
+			{
+				Name:    "Amsterdam",
+				Country: "Netherlands",
+				Sponsor: "Example",
+				URL:     "https://example.com/upload.php",
+			},
+		},
+		ClientIP:  "1.2.3.4",
+		ISP:       "Example ISP",
+		IPVersion: 4,
+	}
+	result.UploadSpread = measurement.Spread{Min: 10_000_000, Max: 11_000_000, StdDev: 500_000}
+
+	tableTests := map[string]struct {
+		format         string
+		expectedOutput string
+	}{
+		"success-human": {
+			format: formatHuman,
+			expectedOutput: "Tool:      ookla\n" +
+				"Server:    Amsterdam, Netherlands (Example)\n" +
+				"Client:    1.2.3.4 (Example ISP, IPv4)\n" +
+				"Latency:   12ms (jitter 1.5ms)\n" +
+				"Download:  94.200 Mbps\n" +
+				"Upload:    10.500 Mbps (min 10.000 Mbps, max 11.000 Mbps, stddev 500.000 Kbps)\n" +
+				"Duration:  10s\n",
+		},
+		"success-csv": {
+			format: formatCSV,
+			expectedOutput: "timestamp,tool,download_bps,upload_bps,latency_ms,jitter_ms," +
+				"bytes_received,bytes_sent,duration_ms,client_ip,isp,ip_version\n" +
+				"2022-08-01T12:00:00Z,ookla,94200000,10500000,12.000,1.500,1000,500,10000.000,1.2.3.4,Example ISP,4\n",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			err := writeResult(buf, testCase.format, result)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedOutput, buf.String())
+		})
+	}
+}
+
+func TestWriteResultDownloadOnly(t *testing.T) {
+	result := &speedtest.Result{
+		Tool:          "ookla",
+		Timestamp:     time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC),
+		Duration:      10 * time.Second,
+		Phases:        []measurement.Phase{speedtest.PhaseDownload},
+		Download:      94_200_000,
+		BytesReceived: 1000,
+	}
+
+	tableTests := map[string]struct {
+		format         string
+		expectedOutput string
+	}{
+		"success-human": {
+			format: formatHuman,
+			expectedOutput: "Tool:      ookla\n" +
+				"Download:  94.200 Mbps\n" +
+				"Duration:  10s\n",
+		},
+		"success-csv": {
+			format: formatCSV,
+			expectedOutput: "timestamp,tool,download_bps,upload_bps,latency_ms,jitter_ms," +
+				"bytes_received,bytes_sent,duration_ms,client_ip,isp,ip_version\n" +
+				"2022-08-01T12:00:00Z,ookla,94200000,,,,1000,,10000.000,,,0\n",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			err := writeResult(buf, testCase.format, result)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedOutput, buf.String())
+		})
+	}
+
+	t.Run("success-json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		err := writeResult(buf, formatJSON, result)
+		assert.NoError(t, err)
+
+		out := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, 94_200_000.0, out["Download"])
+		assert.Equal(t, 1000.0, out["BytesReceived"])
+		for _, key := range []string{"Upload", "UploadSpread", "BytesSent", "Latency", "Jitter"} {
+			value, ok := out[key]
+			assert.True(t, ok, key)
+			assert.Nil(t, value, key)
+		}
+	})
+}
+
+func TestOptions(t *testing.T) {
+	tableTests := map[string]struct {
+		flags       flags
+		expectedErr string
+	}{
+		"success": {
+			flags: flags{
+				serverCount: 1,
+				phases:      "latency, download",
+				format:      formatJSON,
+				aggregation: "p90",
+			},
+		},
+		"error-unknown-phase": {
+			flags: flags{
+				serverCount: 1,
+				phases:      "latency,jitter",
+				format:      formatJSON,
+			},
+			expectedErr: `unknown phase "jitter"`,
+		},
+		"error-unknown-format": {
+			flags: flags{
+				serverCount: 1,
+				phases:      "latency",
+				format:      "xml",
+			},
+			expectedErr: `unknown format "xml"`,
+		},
+		"error-unknown-aggregation": {
+			flags: flags{
+				serverCount: 1,
+				phases:      "download",
+				format:      formatJSON,
+				aggregation: "p101",
+			},
+			expectedErr: `unknown aggregation "p101"`,
+		},
+		"error-zero-servers": {
+			flags: flags{
+				phases: "latency",
+				format: formatJSON,
+			},
+			expectedErr: "amount of servers should be positive",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			_, err := testCase.flags.options()
+			if testCase.expectedErr != "" {
+				assert.Equal(t, testCase.expectedErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
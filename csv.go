@@ -0,0 +1,106 @@
+package speedtest
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader is a header of CSV rows written by CSVWriter,
+// columns are only appended to keep it stable
+var csvHeader = []string{
+	"timestamp",
+	"tool",
+	"download_bps",
+	"upload_bps",
+	"latency_ms",
+	"jitter_ms",
+	"bytes_received",
+	"bytes_sent",
+	"duration_ms",
+	"client_ip",
+	"isp",
+	"ip_version",
+}
+
+// CSVHeader returns header of CSV rows written by CSVWriter
+func CSVHeader() []string {
+	header := make([]string, len(csvHeader))
+	copy(header, csvHeader)
+
+	return header
+}
+
+// CSVWriter writes results as CSV rows, header
+// is written before the first row
+type CSVWriter struct {
+	w *csv.Writer
+
+	// headerWritten is set after header is written
+	headerWritten bool
+}
+
+// NewCSVWriter creates CSVWriter writing to w, set skipHeader
+// if w already contains header, e.g. when appending to a file
+func NewCSVWriter(w io.Writer, skipHeader bool) *CSVWriter {
+	return &CSVWriter{
+		w:             csv.NewWriter(w),
+		headerWritten: skipHeader,
+	}
+}
+
+// Write writes result as a single CSV row and flushes it
+func (w *CSVWriter) Write(result *Result) error {
+	if !w.headerWritten {
+		if err := w.w.Write(csvHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	if err := w.w.Write(result.csvRow()); err != nil {
+		return err
+	}
+	w.w.Flush()
+
+	return w.w.Error()
+}
+
+// csvRow returns result as CSV row matching csvHeader,
+// cells of skipped phases are left empty
+func (r *Result) csvRow() []string {
+	var download, upload, latency, jitter, received, sent string
+	if r.Ran(PhaseLatency) {
+		latency = milliseconds(r.Latency)
+		jitter = milliseconds(r.Jitter)
+	}
+	if r.Ran(PhaseDownload) {
+		download = strconv.FormatFloat(float64(r.Download), 'f', 0, 64)
+		received = strconv.FormatInt(r.BytesReceived, 10)
+	}
+	if r.Ran(PhaseUpload) {
+		upload = strconv.FormatFloat(float64(r.Upload), 'f', 0, 64)
+		sent = strconv.FormatInt(r.BytesSent, 10)
+	}
+
+	return []string{
+		r.Timestamp.Format(time.RFC3339),
+		r.Tool,
+		download,
+		upload,
+		latency,
+		jitter,
+		received,
+		sent,
+		milliseconds(r.Duration),
+		r.ClientIP,
+		r.ISP,
+		strconv.Itoa(r.IPVersion),
+	}
+}
+
+// milliseconds formats duration as a fractional amount of milliseconds
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
@@ -0,0 +1,62 @@
+package speedtest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVWriter(t *testing.T) {
+	result := &Result{
+		Tool:          "ookla",
+		Timestamp:     time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC),
+		Duration:      10 * time.Second,
+		Download:      94_200_000,
+		Upload:        10_500_000,
+		Latency:       12 * time.Millisecond,
+		Jitter:        1500 * time.Microsecond,
+		BytesReceived: 1000,
+		BytesSent:     500,
+		ClientIP:      "1.2.3.4",
+		ISP:           "Example, ISP",
+		IPVersion:     4,
+	}
+	row := "2022-08-01T12:00:00Z,ookla,94200000,10500000,12.000,1.500,1000,500,10000.000,1.2.3.4,\"Example, ISP\",4\n"
+
+	tableTests := map[string]struct {
+		skipHeader     bool
+		expectedOutput string
+	}{
+		"success-with-header": {
+			skipHeader: false,
+			expectedOutput: "timestamp,tool,download_bps,upload_bps,latency_ms,jitter_ms," +
+				"bytes_received,bytes_sent,duration_ms,client_ip,isp,ip_version\n" + row + row,
+		},
+		"success-skip-header": {
+			skipHeader:     true,
+			expectedOutput: row + row,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := NewCSVWriter(buf, testCase.skipHeader)
+
+			assert.NoError(t, w.Write(result))
+			assert.NoError(t, w.Write(result))
+			assert.Equal(t, testCase.expectedOutput, buf.String())
+		})
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	header := CSVHeader()
+	header[0] = "changed"
+
+	assert.Equal(t, "timestamp", CSVHeader()[0])
+	assert.Len(t, CSVHeader(), len((&Result{}).csvRow()))
+}
@@ -0,0 +1,26 @@
+package speedtest
+
+import "github.com/bejaneps/speedtest/internal/measurement"
+
+var (
+	// ErrServerDiscovery is matched by errors of requesting
+	// list of servers from measurement tool
+	ErrServerDiscovery = measurement.ErrServerDiscovery
+
+	// ErrNoServers is matched by errors of empty server
+	// list or of servers that are all unreachable
+	ErrNoServers = measurement.ErrNoServers
+
+	// ErrTokenInvalid is matched by errors of authentication
+	// token rejected by measurement tool
+	ErrTokenInvalid = measurement.ErrTokenInvalid
+
+	// ErrTransfer is matched by errors of download, upload
+	// or latency measurement against server
+	ErrTransfer = measurement.ErrTransfer
+)
+
+// MeasurementError describes failed measurement with its phase,
+// server, HTTP status code and underlying error, it can be
+// extracted from returned errors with errors.As
+type MeasurementError = measurement.MeasurementError
@@ -0,0 +1,196 @@
+// Package exporter exposes results of speedtest measurements
+// in Prometheus text exposition format
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+const defaultTimeout = 2 * time.Minute
+
+// Option configures Exporter
+type Option func(*Exporter)
+
+// WithInterval makes exporter measure every interval in background
+// once Run is called, scrapes are served from the latest result.
+// If interval isn't set, then measurement is run on every scrape
+func WithInterval(interval time.Duration) Option {
+	return func(e *Exporter) {
+		e.interval = interval
+	}
+}
+
+// WithTimeout sets timeout of a single measurement run,
+// default timeout is 2 minutes
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *Exporter) {
+		e.timeout = timeout
+	}
+}
+
+// WithMeasureOptions sets options passed to speedtest.Measure,
+// e.g. speedtest.WithPhases to limit measured phases
+func WithMeasureOptions(opts ...config.Option) Option {
+	return func(e *Exporter) {
+		e.measureOpts = opts
+	}
+}
+
+// WithErrorHandler sets function called when
+// measurement fails, errors are printed by default
+func WithErrorHandler(fn func(error)) Option {
+	return func(e *Exporter) {
+		e.onError = fn
+	}
+}
+
+// Exporter runs measurements using measurer and serves their
+// results as Prometheus metrics, it implements http.Handler
+type Exporter struct {
+	tool     string
+	measurer speedtest.Measurer
+
+	interval    time.Duration
+	timeout     time.Duration
+	measureOpts []config.Option
+	onError     func(error)
+
+	// runMu ensures that only one measurement runs at a time
+	runMu sync.Mutex
+
+	// result is the latest successful result, its
+	// gauges are only exported while exporter is up
+	mu             sync.Mutex
+	result         *speedtest.Result
+	runs           uint64
+	failures       uint64
+	serverFailures map[serverKey]uint64
+	up             bool
+}
+
+// serverKey identifies server in phase for counting its failures
+type serverKey struct {
+	phase  string
+	server string
+}
+
+// New creates exporter for measurer, tool is a name
+// of measurement tool used as a metric label
+func New(tool string, measurer speedtest.Measurer, opts ...Option) *Exporter {
+	e := &Exporter{
+		tool:           tool,
+		measurer:       measurer,
+		timeout:        defaultTimeout,
+		serverFailures: make(map[serverKey]uint64),
+		onError: func(err error) {
+			fmt.Printf("measurement failed: %v\n", err)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Run measures every interval until ctx is done, the first
+// measurement is run immediately. It returns immediately
+// if interval isn't set, since exporter measures on scrape
+func (e *Exporter) Run(ctx context.Context) error {
+	if e.interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.measure(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler, it writes metrics of the
+// latest measurement, measuring first if interval isn't set
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if e.interval <= 0 {
+		e.measure(r.Context())
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if err := e.write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// measure runs measurement and records its result, failed
+// servers are counted whether measurement succeeded or not
+func (e *Exporter) measure(ctx context.Context) {
+	e.runMu.Lock()
+	defer e.runMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	result, err := speedtest.Measure(ctx, e.measurer, e.measureOpts...)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.runs++
+	e.countServerFailures(result, err)
+	if err != nil {
+		e.failures++
+		e.up = false
+		e.onError(err)
+		return
+	}
+
+	result.Tool = e.tool
+	e.result = result
+	e.up = true
+}
+
+// countServerFailures counts failed transfers of result, if error
+// is caused by server without transfer details, e.g. in latency
+// phase, then it's counted too
+func (e *Exporter) countServerFailures(result *speedtest.Result, err error) {
+	counted := false
+	if result != nil {
+		for _, transfer := range result.DownloadServers {
+			if transfer.Err != nil {
+				e.serverFailures[serverKey{string(speedtest.PhaseDownload), transfer.Server.URL}]++
+				counted = true
+			}
+		}
+		for _, transfer := range result.UploadServers {
+			if transfer.Err != nil {
+				e.serverFailures[serverKey{string(speedtest.PhaseUpload), transfer.Server.URL}]++
+				counted = true
+			}
+		}
+	}
+	if counted {
+		return
+	}
+
+	var merr *measurement.MeasurementError
+	if errors.As(err, &merr) && errors.Is(merr, measurement.ErrTransfer) && merr.Phase != "" && merr.Server != "" {
+		e.serverFailures[serverKey{string(merr.Phase), merr.Server}]++
+	}
+}
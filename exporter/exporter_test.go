@@ -0,0 +1,240 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMeasurer returns preset results of measurements,
+// downloadErr is returned only by download measurement
+type stubMeasurer struct {
+	download    measurement.Transfer
+	upload      measurement.Transfer
+	latency     measurement.Latency
+	err         error
+	downloadErr error
+}
+
+func (m *stubMeasurer) MeasureDownload(ctx context.Context) (measurement.BitRate, error) {
+	return m.download.Rate, m.err
+}
+
+func (m *stubMeasurer) MeasureUpload(ctx context.Context) (measurement.BitRate, error) {
+	return m.upload.Rate, m.err
+}
+
+func (m *stubMeasurer) MeasureLatency(ctx context.Context) (measurement.Latency, error) {
+	return m.latency, m.err
+}
+
+func (m *stubMeasurer) MeasureDownloadTransfer(ctx context.Context) (measurement.Transfer, error) {
+	if m.downloadErr != nil {
+		return m.download, m.downloadErr
+	}
+	return m.download, m.err
+}
+
+func (m *stubMeasurer) MeasureUploadTransfer(ctx context.Context) (measurement.Transfer, error) {
+	return m.upload, m.err
+}
+
+func TestServeHTTP(t *testing.T) {
+	tableTests := map[string]struct {
+		measurer        *stubMeasurer
+		options         []Option
+		expectedMetrics []string
+		excludedMetrics []string
+	}{
+		"success": {
+			measurer: &stubMeasurer{
+				download: measurement.Transfer{
+					Rate:  94_200_000,
+					Bytes: 1000,
+					Servers: []measurement.ServerTransfer{
+						{Server: measurement.Server{URL: "https://a.example.com"}, Rate: 94_200_000},
+						{Server: measurement.Server{URL: `https://b.example.com/"q"`}, Err: errors.New("random error")},
+					},
+				},
+				upload: measurement.Transfer{
+					Rate:  10_000_000,
+					Bytes: 500,
+				},
+				latency: measurement.Latency{
+					Avg:    15 * time.Millisecond,
+					Jitter: 2 * time.Millisecond,
+				},
+			},
+			expectedMetrics: []string{
+				`speedtest_up{tool="ookla"} 1`,
+				`speedtest_runs_total{tool="ookla"} 1`,
+				`speedtest_failures_total{tool="ookla"} 0`,
+				`speedtest_download_bits_per_second{tool="ookla"} 9.42e+07`,
+				`speedtest_upload_bits_per_second{tool="ookla"} 1e+07`,
+				`speedtest_latency_seconds{tool="ookla"} 0.015`,
+				`speedtest_jitter_seconds{tool="ookla"} 0.002`,
+				`speedtest_download_bytes{tool="ookla"} 1000`,
+				`speedtest_upload_bytes{tool="ookla"} 500`,
+				`speedtest_server_download_bits_per_second{tool="ookla",server="https://a.example.com"} 9.42e+07`,
+				`speedtest_server_failures_total{tool="ookla",phase="download",server="https://b.example.com/\"q\""} 1`,
+				"# TYPE speedtest_runs_total counter",
+			},
+			excludedMetrics: []string{
+				"speedtest_server_upload_bits_per_second",
+			},
+		},
+		"success-upload-skipped": {
+			measurer: &stubMeasurer{
+				download: measurement.Transfer{
+					Rate:  94_200_000,
+					Bytes: 1000,
+				},
+				latency: measurement.Latency{
+					Avg:    15 * time.Millisecond,
+					Jitter: 2 * time.Millisecond,
+				},
+			},
+			options: []Option{
+				WithMeasureOptions(speedtest.WithPhases(speedtest.PhaseLatency, speedtest.PhaseDownload)),
+			},
+			expectedMetrics: []string{
+				`speedtest_up{tool="ookla"} 1`,
+				`speedtest_download_bits_per_second{tool="ookla"} 9.42e+07`,
+				`speedtest_download_bytes{tool="ookla"} 1000`,
+				`speedtest_latency_seconds{tool="ookla"} 0.015`,
+			},
+			excludedMetrics: []string{
+				"speedtest_upload_bits_per_second",
+				"speedtest_upload_bytes",
+			},
+		},
+		"error-measurement-failed": {
+			measurer: &stubMeasurer{
+				err: errors.New("random error"),
+			},
+			expectedMetrics: []string{
+				`speedtest_up{tool="ookla"} 0`,
+				`speedtest_runs_total{tool="ookla"} 1`,
+				`speedtest_failures_total{tool="ookla"} 1`,
+			},
+			excludedMetrics: []string{
+				"speedtest_download_bits_per_second",
+			},
+		},
+		"error-transfer-failed-fail-fast": {
+			measurer: &stubMeasurer{
+				download: measurement.Transfer{
+					Servers: []measurement.ServerTransfer{
+						{Server: measurement.Server{URL: "https://a.example.com"}, Err: errors.New("random error")},
+					},
+				},
+				downloadErr: errors.New("random error"),
+			},
+			expectedMetrics: []string{
+				`speedtest_up{tool="ookla"} 0`,
+				`speedtest_failures_total{tool="ookla"} 1`,
+				`speedtest_server_failures_total{tool="ookla",phase="download",server="https://a.example.com"} 1`,
+			},
+			excludedMetrics: []string{
+				"speedtest_download_bits_per_second",
+			},
+		},
+		"error-latency-failed": {
+			measurer: &stubMeasurer{
+				err: &measurement.MeasurementError{
+					Kind:   measurement.ErrTransfer,
+					Phase:  measurement.PhaseLatency,
+					Server: "https://a.example.com",
+					Err:    errors.New("random error"),
+				},
+			},
+			expectedMetrics: []string{
+				`speedtest_up{tool="ookla"} 0`,
+				`speedtest_failures_total{tool="ookla"} 1`,
+				`speedtest_server_failures_total{tool="ookla",phase="latency",server="https://a.example.com"} 1`,
+			},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			var errs []error
+			opts := append([]Option{WithErrorHandler(func(err error) {
+				errs = append(errs, err)
+			})}, testCase.options...)
+			e := New("ookla", testCase.measurer, opts...)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+
+			lines := strings.Split(rec.Body.String(), "\n")
+			for _, metric := range testCase.expectedMetrics {
+				assert.Contains(t, lines, metric)
+			}
+			for _, metric := range testCase.excludedMetrics {
+				assert.NotContains(t, rec.Body.String(), metric)
+			}
+
+			// failed measurements are reported to error handler
+			assert.Len(t, errs, int(e.failures))
+		})
+	}
+}
+
+func TestServeHTTPAfterFailure(t *testing.T) {
+	measurer := &stubMeasurer{
+		download: measurement.Transfer{Rate: 94_200_000},
+	}
+
+	var errs []error
+	e := New("ookla", measurer, WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `speedtest_download_bits_per_second{tool="ookla"} 9.42e+07`)
+
+	measurer.err = errors.New("random error")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// gauges of stale result aren't exported, while
+	// time of the latest success still is
+	assert.Contains(t, rec.Body.String(), `speedtest_up{tool="ookla"} 0`)
+	assert.Contains(t, rec.Body.String(), "speedtest_last_run_timestamp_seconds")
+	assert.NotContains(t, rec.Body.String(), "speedtest_download_bits_per_second")
+	assert.Len(t, errs, 1)
+}
+
+func TestRun(t *testing.T) {
+	e := New("netflix", &stubMeasurer{}, WithInterval(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Millisecond)
+	defer cancel()
+
+	err := e.Run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// scrapes are served from the latest result without measuring
+	runs := e.runs
+	assert.GreaterOrEqual(t, runs, uint64(2))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, runs, e.runs)
+	assert.Contains(t, rec.Body.String(), `speedtest_up{tool="netflix"} 1`)
+}
@@ -0,0 +1,156 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+// contentType is a content type of Prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsWriter writes metrics in Prometheus text exposition format
+type metricsWriter struct {
+	w *bufio.Writer
+}
+
+// header writes help and type of metric
+func (mw metricsWriter) header(name, typ, help string) {
+	fmt.Fprintf(mw.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes sample of metric with labels,
+// labels are provided as name and value pairs
+func (mw metricsWriter) sample(name string, value float64, labels ...string) {
+	mw.w.WriteString(name)
+
+	if len(labels) > 0 {
+		mw.w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				mw.w.WriteByte(',')
+			}
+			fmt.Fprintf(mw.w, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		mw.w.WriteByte('}')
+	}
+
+	mw.w.WriteByte(' ')
+	mw.w.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	mw.w.WriteByte('\n')
+}
+
+// metric writes metric with single sample
+func (mw metricsWriter) metric(name, typ, help string, value float64, labels ...string) {
+	mw.header(name, typ, help)
+	mw.sample(name, value, labels...)
+}
+
+// write writes metrics of the latest measurement
+func (e *Exporter) write(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mw := metricsWriter{w: bufio.NewWriter(w)}
+	tool := []string{"tool", e.tool}
+
+	up := 0.0
+	if e.up {
+		up = 1
+	}
+	mw.metric("speedtest_up", "gauge",
+		"Whether the latest measurement succeeded.", up, tool...)
+	mw.metric("speedtest_runs_total", "counter",
+		"Total number of measurements.", float64(e.runs), tool...)
+	mw.metric("speedtest_failures_total", "counter",
+		"Total number of failed measurements.", float64(e.failures), tool...)
+
+	if len(e.serverFailures) > 0 {
+		keys := make([]serverKey, 0, len(e.serverFailures))
+		for key := range e.serverFailures {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].phase != keys[j].phase {
+				return keys[i].phase < keys[j].phase
+			}
+			return keys[i].server < keys[j].server
+		})
+
+		mw.header("speedtest_server_failures_total", "counter",
+			"Total number of failed transfers per server.")
+		for _, key := range keys {
+			mw.sample("speedtest_server_failures_total", float64(e.serverFailures[key]),
+				"tool", e.tool, "phase", key.phase, "server", key.server)
+		}
+	}
+
+	// timestamp of the latest success is exported even if
+	// the latest measurement failed, so staleness is visible
+	if r := e.result; r != nil {
+		mw.metric("speedtest_last_run_timestamp_seconds", "gauge",
+			"Time when the latest successful measurement started.",
+			float64(r.Timestamp.UnixNano())/1e9, tool...)
+	}
+
+	// gauges of stale results aren't exported after failure,
+	// and gauges of skipped phases aren't exported at all
+	if r := e.result; r != nil && e.up {
+		mw.metric("speedtest_run_duration_seconds", "gauge",
+			"Duration of the latest successful measurement.", r.Duration.Seconds(), tool...)
+
+		if r.Ran(speedtest.PhaseLatency) {
+			mw.metric("speedtest_latency_seconds", "gauge",
+				"Average round trip time.", r.Latency.Seconds(), tool...)
+			mw.metric("speedtest_jitter_seconds", "gauge",
+				"Jitter of round trip time.", r.Jitter.Seconds(), tool...)
+		}
+		if r.Ran(speedtest.PhaseDownload) {
+			mw.metric("speedtest_download_bits_per_second", "gauge",
+				"Download bit rate.", float64(r.Download), tool...)
+			mw.metric("speedtest_download_bytes", "gauge",
+				"Bytes received during download measurement.", float64(r.BytesReceived), tool...)
+			mw.serverRates("speedtest_server_download_bits_per_second",
+				"Download bit rate per server.", e.tool, r.DownloadServers)
+		}
+		if r.Ran(speedtest.PhaseUpload) {
+			mw.metric("speedtest_upload_bits_per_second", "gauge",
+				"Upload bit rate.", float64(r.Upload), tool...)
+			mw.metric("speedtest_upload_bytes", "gauge",
+				"Bytes sent during upload measurement.", float64(r.BytesSent), tool...)
+			mw.serverRates("speedtest_server_upload_bits_per_second",
+				"Upload bit rate per server.", e.tool, r.UploadServers)
+		}
+	}
+
+	return mw.w.Flush()
+}
+
+// serverRates writes rates of successful server transfers
+func (mw metricsWriter) serverRates(name, help, tool string, transfers []measurement.ServerTransfer) {
+	headerWritten := false
+	for _, transfer := range transfers {
+		if transfer.Err != nil {
+			continue
+		}
+
+		if !headerWritten {
+			mw.header(name, "gauge", help)
+			headerWritten = true
+		}
+		mw.sample(name, float64(transfer.Rate), "tool", tool, "server", transfer.Server.URL)
+	}
+}
+
+// labelReplacer escapes label values as required by exposition format
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelReplacer.Replace(value)
+}
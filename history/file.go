@@ -0,0 +1,95 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/bejaneps/speedtest"
+)
+
+// File is a store appending results to a file in JSON lines
+// format, a single JSON encoded result per line
+type File struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFile creates file store at path, file
+// is created when the first result is added
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Add appends result to the file
+func (f *File) Add(result *speedtest.Result) error {
+	line, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close history file: %w", err)
+	}
+
+	return nil
+}
+
+// Results reads all results from the file, no
+// results are returned if file doesn't exist yet
+func (f *File) Results() ([]*speedtest.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Printf("failed to close history file: %v\n", err)
+		}
+	}()
+
+	var results []*speedtest.Result
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		result := &speedtest.Result{}
+		if err := json.Unmarshal(scanner.Bytes(), result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result at line %d: %w", line, err)
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return results, nil
+}
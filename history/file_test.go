@@ -0,0 +1,50 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := NewFile(path)
+
+	stored, err := store.Results()
+	assert.NoError(t, err)
+	assert.Empty(t, stored)
+
+	added := results(3)
+	added[2].DownloadServers = []measurement.ServerTransfer{
+		{
+			Server: measurement.Server{URL: "https://example.com"},
+			Err:    errors.New("random error"),
+		},
+	}
+	for _, result := range added {
+		assert.NoError(t, store.Add(result))
+	}
+
+	// results are read back by another store of the same file
+	stored, err = NewFile(path).Results()
+	assert.NoError(t, err)
+	assert.Len(t, stored, 3)
+	for i := range added {
+		assert.True(t, added[i].Timestamp.Equal(stored[i].Timestamp))
+		assert.Equal(t, added[i].Download, stored[i].Download)
+		assert.Equal(t, added[i].Latency, stored[i].Latency)
+	}
+	assert.EqualError(t, stored[2].DownloadServers[0].Err, "random error")
+}
+
+func TestFileCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("{}\nnot json\n"), 0o644))
+
+	_, err := NewFile(path).Results()
+	assert.ErrorContains(t, err, "failed to unmarshal result at line 2")
+}
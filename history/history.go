@@ -0,0 +1,180 @@
+// Package history stores results of speedtest runs
+// and provides queries over them
+package history
+
+import (
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+// Store stores results of runs, implementations
+// should be safe for concurrent use
+type Store interface {
+	// Add stores result
+	Add(result *speedtest.Result) error
+
+	// Results returns stored results in order they were added
+	Results() ([]*speedtest.Result, error)
+}
+
+// Last returns last n results of store, negative
+// n is treated as zero, so no results are returned
+func Last(store Store, n int) ([]*speedtest.Result, error) {
+	results, err := store.Results()
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	if n < len(results) {
+		results = results[len(results)-n:]
+	}
+
+	return results, nil
+}
+
+// Window returns results of store with timestamp in range [from, to)
+func Window(store Store, from, to time.Time) ([]*speedtest.Result, error) {
+	results, err := store.Results()
+	if err != nil {
+		return nil, err
+	}
+
+	var window []*speedtest.Result
+	for _, result := range results {
+		if !result.Timestamp.Before(from) && result.Timestamp.Before(to) {
+			window = append(window, result)
+		}
+	}
+
+	return window, nil
+}
+
+// RateStats represents statistics of bit rates
+type RateStats struct {
+	Min measurement.BitRate
+	Avg measurement.BitRate
+	Max measurement.BitRate
+}
+
+// DurationStats represents statistics of durations
+type DurationStats struct {
+	Min time.Duration
+	Avg time.Duration
+	Max time.Duration
+}
+
+// Aggregate represents statistics of multiple results
+type Aggregate struct {
+	// Count is an amount of aggregated results
+	Count int
+
+	From time.Time
+	To   time.Time
+
+	// statistics of each metric are calculated only over results
+	// whose phase measuring it ran, and are zero if there are none
+	Download RateStats
+	Upload   RateStats
+	Latency  DurationStats
+	Jitter   DurationStats
+}
+
+// Summarize aggregates results of store with timestamp in range [from, to)
+func Summarize(store Store, from, to time.Time) (Aggregate, error) {
+	results, err := Window(store, from, to)
+	if err != nil {
+		return Aggregate{}, err
+	}
+
+	return aggregate(results, from, to), nil
+}
+
+// aggregate calculates statistics of results
+func aggregate(results []*speedtest.Result, from, to time.Time) Aggregate {
+	agg := Aggregate{
+		Count: len(results),
+		From:  from,
+		To:    to,
+	}
+	if len(results) == 0 {
+		return agg
+	}
+
+	downloads := make([]float64, 0, len(results))
+	uploads := make([]float64, 0, len(results))
+	latencies := make([]float64, 0, len(results))
+	jitters := make([]float64, 0, len(results))
+	for _, result := range results {
+		// zero values of skipped phases aren't measurements
+		if result.Ran(speedtest.PhaseDownload) {
+			downloads = append(downloads, float64(result.Download))
+		}
+		if result.Ran(speedtest.PhaseUpload) {
+			uploads = append(uploads, float64(result.Upload))
+		}
+		if result.Ran(speedtest.PhaseLatency) {
+			latencies = append(latencies, float64(result.Latency))
+			jitters = append(jitters, float64(result.Jitter))
+		}
+	}
+
+	minValue, avgValue, maxValue := stats(downloads)
+	agg.Download = RateStats{
+		Min: measurement.BitRate(minValue),
+		Avg: measurement.BitRate(avgValue),
+		Max: measurement.BitRate(maxValue),
+	}
+
+	minValue, avgValue, maxValue = stats(uploads)
+	agg.Upload = RateStats{
+		Min: measurement.BitRate(minValue),
+		Avg: measurement.BitRate(avgValue),
+		Max: measurement.BitRate(maxValue),
+	}
+
+	minValue, avgValue, maxValue = stats(latencies)
+	agg.Latency = DurationStats{
+		Min: time.Duration(minValue),
+		Avg: time.Duration(avgValue),
+		Max: time.Duration(maxValue),
+	}
+
+	minValue, avgValue, maxValue = stats(jitters)
+	agg.Jitter = DurationStats{
+		Min: time.Duration(minValue),
+		Avg: time.Duration(avgValue),
+		Max: time.Duration(maxValue),
+	}
+
+	return agg
+}
+
+// stats returns minimum, average and maximum of values,
+// all of them are zero if there are no values
+func stats(values []float64) (minValue, avgValue, maxValue float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+
+	minValue, maxValue = values[0], values[0]
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+
+		if value < minValue {
+			minValue = value
+		}
+		if value > maxValue {
+			maxValue = value
+		}
+	}
+
+	return minValue, sum / float64(len(values)), maxValue
+}
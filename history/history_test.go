@@ -0,0 +1,169 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+)
+
+var baseTime = time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+
+// results returns n results taken every hour starting from baseTime
+func results(n int) []*speedtest.Result {
+	results := make([]*speedtest.Result, 0, n)
+	for i := 0; i < n; i++ {
+		results = append(results, &speedtest.Result{
+			Tool:      "ookla",
+			Timestamp: baseTime.Add(time.Duration(i) * time.Hour),
+			Download:  measurement.BitRate((i + 1) * 1000),
+			Upload:    measurement.BitRate((i + 1) * 100),
+			Latency:   time.Duration(i+1) * time.Millisecond,
+			Jitter:    time.Duration(i+1) * time.Microsecond,
+		})
+	}
+
+	return results
+}
+
+func TestLast(t *testing.T) {
+	store := NewRing(10)
+	for _, result := range results(5) {
+		assert.NoError(t, store.Add(result))
+	}
+
+	tableTests := map[string]struct {
+		n                 int
+		expectedFirstTime time.Time
+		expectedLen       int
+	}{
+		"success-fewer-than-stored": {
+			n:                 2,
+			expectedFirstTime: baseTime.Add(3 * time.Hour),
+			expectedLen:       2,
+		},
+		"success-more-than-stored": {
+			n:                 10,
+			expectedFirstTime: baseTime,
+			expectedLen:       5,
+		},
+		"success-zero": {
+			n:           0,
+			expectedLen: 0,
+		},
+		"success-negative": {
+			n:           -1,
+			expectedLen: 0,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			last, err := Last(store, testCase.n)
+			assert.NoError(t, err)
+			assert.Len(t, last, testCase.expectedLen)
+			if testCase.expectedLen > 0 {
+				assert.Equal(t, testCase.expectedFirstTime, last[0].Timestamp)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	store := NewRing(10)
+	for _, result := range results(5) {
+		assert.NoError(t, store.Add(result))
+	}
+
+	tableTests := map[string]struct {
+		from, to          time.Time
+		expectedAggregate Aggregate
+	}{
+		"success-window": {
+			from: baseTime.Add(time.Hour),
+			to:   baseTime.Add(4 * time.Hour),
+			expectedAggregate: Aggregate{
+				Count:    3,
+				From:     baseTime.Add(time.Hour),
+				To:       baseTime.Add(4 * time.Hour),
+				Download: RateStats{Min: 2000, Avg: 3000, Max: 4000},
+				Upload:   RateStats{Min: 200, Avg: 300, Max: 400},
+				Latency: DurationStats{
+					Min: 2 * time.Millisecond,
+					Avg: 3 * time.Millisecond,
+					Max: 4 * time.Millisecond,
+				},
+				Jitter: DurationStats{
+					Min: 2 * time.Microsecond,
+					Avg: 3 * time.Microsecond,
+					Max: 4 * time.Microsecond,
+				},
+			},
+		},
+		"success-empty-window": {
+			from: baseTime.Add(-2 * time.Hour),
+			to:   baseTime,
+			expectedAggregate: Aggregate{
+				From: baseTime.Add(-2 * time.Hour),
+				To:   baseTime,
+			},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			agg, err := Summarize(store, testCase.from, testCase.to)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedAggregate, agg)
+		})
+	}
+}
+
+func TestSummarizeSkippedPhases(t *testing.T) {
+	store := NewRing(10)
+	for i, result := range results(4) {
+		// the first two results measured only download, so
+		// their zero upload and latency aren't aggregated
+		if i < 2 {
+			result.Phases = []speedtest.Phase{speedtest.PhaseDownload}
+		}
+		assert.NoError(t, store.Add(result))
+	}
+
+	agg, err := Summarize(store, baseTime, baseTime.Add(4*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, Aggregate{
+		Count:    4,
+		From:     baseTime,
+		To:       baseTime.Add(4 * time.Hour),
+		Download: RateStats{Min: 1000, Avg: 2500, Max: 4000},
+		Upload:   RateStats{Min: 300, Avg: 350, Max: 400},
+		Latency: DurationStats{
+			Min: 3 * time.Millisecond,
+			Avg: 3500 * time.Microsecond,
+			Max: 4 * time.Millisecond,
+		},
+		Jitter: DurationStats{
+			Min: 3 * time.Microsecond,
+			Avg: 3500 * time.Nanosecond,
+			Max: 4 * time.Microsecond,
+		},
+	}, agg)
+
+	// phase skipped by every result has zero statistics
+	store = NewRing(10)
+	for _, result := range results(2) {
+		result.Phases = []speedtest.Phase{speedtest.PhaseLatency}
+		assert.NoError(t, store.Add(result))
+	}
+
+	agg, err = Summarize(store, baseTime, baseTime.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, RateStats{}, agg.Download)
+	assert.Equal(t, RateStats{}, agg.Upload)
+	assert.Equal(t, 1500*time.Microsecond, agg.Latency.Avg)
+}
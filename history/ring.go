@@ -0,0 +1,61 @@
+package history
+
+import (
+	"sync"
+
+	"github.com/bejaneps/speedtest"
+)
+
+// Ring is an in-memory store keeping limited amount
+// of the latest results, older results are dropped
+type Ring struct {
+	mu      sync.Mutex
+	results []*speedtest.Result
+
+	// next is an index where next result is stored
+	next int
+	full bool
+}
+
+// NewRing creates ring store keeping up to capacity results
+func NewRing(capacity int) *Ring {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &Ring{
+		results: make([]*speedtest.Result, capacity),
+	}
+}
+
+// Add stores result, dropping the oldest one if ring is full
+func (r *Ring) Add(result *speedtest.Result) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results[r.next] = result
+	r.next = (r.next + 1) % len(r.results)
+	if r.next == 0 {
+		r.full = true
+	}
+
+	return nil
+}
+
+// Results returns stored results in order they were added
+func (r *Ring) Results() ([]*speedtest.Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		results := make([]*speedtest.Result, r.next)
+		copy(results, r.results[:r.next])
+		return results, nil
+	}
+
+	results := make([]*speedtest.Result, 0, len(r.results))
+	results = append(results, r.results[r.next:]...)
+	results = append(results, r.results[:r.next]...)
+
+	return results, nil
+}
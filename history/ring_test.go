@@ -0,0 +1,51 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	tableTests := map[string]struct {
+		capacity      int
+		added         int
+		expectedFirst int
+		expectedLen   int
+	}{
+		"success-not-full": {
+			capacity:      5,
+			added:         3,
+			expectedFirst: 0,
+			expectedLen:   3,
+		},
+		"success-full": {
+			capacity:      5,
+			added:         5,
+			expectedFirst: 0,
+			expectedLen:   5,
+		},
+		"success-overwritten": {
+			capacity:      3,
+			added:         7,
+			expectedFirst: 4,
+			expectedLen:   3,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			added := results(testCase.added)
+
+			store := NewRing(testCase.capacity)
+			for _, result := range added {
+				assert.NoError(t, store.Add(result))
+			}
+
+			stored, err := store.Results()
+			assert.NoError(t, err)
+			assert.Equal(t, added[testCase.expectedFirst:testCase.expectedFirst+testCase.expectedLen], stored)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package speedtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+)
+
+const (
+	dialTimeout   = 30 * time.Second
+	dialKeepAlive = 30 * time.Second
+)
+
+// HTTPDoer sends HTTP requests of measurement, *http.Client
+// implements it, so it can be provided with WithHTTPClient
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// newHTTPDoer returns client provided in config or builds one
+// from transport settings, user agent is set in both cases.
+// Transport options and request timeout are applied only to
+// built client, so they are rejected along with custom client
+func newHTTPDoer(conf *config.Config) (config.HTTPDoer, error) {
+	doer := conf.HTTPClient
+	if doer == nil {
+		client, err := newHTTPClient(conf)
+		if err != nil {
+			return nil, err
+		}
+		doer = client
+	} else if conf.Proxy != "" || conf.SourceIP != "" || conf.Interface != "" ||
+		conf.TLSConfig != nil || conf.IPVersion != 0 {
+		return nil, errors.New("transport options can't be used with custom HTTP client")
+	} else if conf.RequestTimeout != 0 {
+		return nil, errors.New("request timeout can't be used with custom HTTP client, set timeout of the client instead")
+	}
+
+	if conf.UserAgent != "" {
+		doer = &userAgentDoer{doer: doer, userAgent: conf.UserAgent}
+	}
+
+	return doer, nil
+}
+
+// newHTTPClient builds client from transport settings of config
+func newHTTPClient(conf *config.Config) (*http.Client, error) {
+	switch conf.IPVersion {
+	case 0, 4, 6:
+	default:
+		return nil, fmt.Errorf("unknown ip version %d", conf.IPVersion)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: dialKeepAlive,
+	}
+
+	if conf.Proxy != "" {
+		proxyURL, err := url.Parse(conf.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	sourceIP, err := resolveSourceIP(conf.SourceIP, conf.Interface, conf.IPVersion)
+	if err != nil {
+		return nil, err
+	}
+	if sourceIP != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: sourceIP}
+	}
+
+	if conf.TLSConfig != nil {
+		transport.TLSClientConfig = conf.TLSConfig
+	}
+
+	// network is restricted for both server discovery and
+	// transfers, since they share the same transport
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, conf.Network(network), addr)
+	}
+
+	timeout := conf.RequestTimeout
+	if timeout <= 0 {
+		timeout = reqTimeoutDuration
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+// resolveSourceIP parses source IP, or finds it among addresses
+// of network interface, address must be of provided ip version
+// if it's set, nil is returned if neither is set
+func resolveSourceIP(sourceIP, interfaceName string, ipVersion int) (net.IP, error) {
+	if sourceIP != "" {
+		ip := net.ParseIP(sourceIP)
+		if ip == nil {
+			return nil, fmt.Errorf("failed to parse source ip %q", sourceIP)
+		}
+		if !matchesIPVersion(ip, ipVersion) {
+			return nil, fmt.Errorf("source ip %q isn't of ip version %d", sourceIP, ipVersion)
+		}
+
+		return ip, nil
+	}
+
+	if interfaceName == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(interfaceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find network interface: %w", err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses of network interface: %w", err)
+	}
+
+	// link-local addresses require zone, so
+	// they aren't used as source address
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && !ipNet.IP.IsLinkLocalUnicast() && matchesIPVersion(ipNet.IP, ipVersion) {
+			return ipNet.IP, nil
+		}
+	}
+
+	return nil, fmt.Errorf("network interface %q has no usable address", interfaceName)
+}
+
+// matchesIPVersion reports whether ip is of provided ip version,
+// any ip matches if version isn't set
+func matchesIPVersion(ip net.IP, ipVersion int) bool {
+	switch ipVersion {
+	case 4:
+		return ip.To4() != nil
+	case 6:
+		return ip.To4() == nil
+	}
+
+	return true
+}
+
+// userAgentDoer sets user agent to requests that don't have one
+type userAgentDoer struct {
+	doer      config.HTTPDoer
+	userAgent string
+}
+
+// Do sets user agent and sends request with underlying doer
+func (d *userAgentDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", d.userAgent)
+	}
+
+	return d.doer.Do(req)
+}
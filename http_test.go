@@ -0,0 +1,194 @@
+package speedtest
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPDoer(t *testing.T) {
+	customClient := &http.Client{}
+
+	tableTests := map[string]struct {
+		conf        config.Config
+		expectedErr string
+	}{
+		"success-default": {
+			conf: config.Config{},
+		},
+		"success-transport-options": {
+			conf: config.Config{
+				Proxy:     "socks5://127.0.0.1:1080",
+				SourceIP:  "127.0.0.1",
+				TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+			},
+		},
+		"success-custom-client": {
+			conf: config.Config{
+				HTTPClient: customClient,
+				UserAgent:  "probe/1.0",
+			},
+		},
+		"error-custom-client-with-transport-options": {
+			conf: config.Config{
+				HTTPClient: customClient,
+				Proxy:      "http://proxy.example.com:3128",
+			},
+			expectedErr: "transport options can't be used with custom HTTP client",
+		},
+		"error-custom-client-with-request-timeout": {
+			conf: config.Config{
+				HTTPClient:     customClient,
+				RequestTimeout: time.Second,
+			},
+			expectedErr: "request timeout can't be used with custom HTTP client, set timeout of the client instead",
+		},
+		"error-invalid-proxy": {
+			conf: config.Config{
+				Proxy: "http://[::1",
+			},
+			expectedErr: `failed to parse proxy url: parse "http://[::1": missing ']' in host`,
+		},
+		"error-invalid-source-ip": {
+			conf: config.Config{
+				SourceIP: "not-an-ip",
+			},
+			expectedErr: `failed to parse source ip "not-an-ip"`,
+		},
+		"error-custom-client-with-ip-version": {
+			conf: config.Config{
+				HTTPClient: customClient,
+				IPVersion:  6,
+			},
+			expectedErr: "transport options can't be used with custom HTTP client",
+		},
+		"error-unknown-ip-version": {
+			conf: config.Config{
+				IPVersion: 5,
+			},
+			expectedErr: "unknown ip version 5",
+		},
+		"error-source-ip-of-other-version": {
+			conf: config.Config{
+				SourceIP:  "127.0.0.1",
+				IPVersion: 6,
+			},
+			expectedErr: `source ip "127.0.0.1" isn't of ip version 6`,
+		},
+		"error-unknown-interface": {
+			conf: config.Config{
+				Interface: "unknown0",
+			},
+			expectedErr: "failed to find network interface: route ip+net: no such network interface",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer, err := newHTTPDoer(&testCase.conf)
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, doer)
+		})
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	client, err := newHTTPClient(&config.Config{
+		SourceIP:       "127.0.0.1",
+		RequestTimeout: 5 * time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	// requests are sent from source ip
+	var remoteAddr string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+	}))
+	defer srv.Close()
+
+	resp, err := client.Get(srv.URL)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+}
+
+func TestUserAgentDoer(t *testing.T) {
+	var userAgents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+	}))
+	defer srv.Close()
+
+	doer, err := newHTTPDoer(&config.Config{UserAgent: "probe/1.0"})
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+	resp, err := doer.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	// user agent set by request isn't overridden
+	req, err = http.NewRequest(http.MethodGet, srv.URL, nil)
+	assert.NoError(t, err)
+	req.Header.Set("User-Agent", "custom")
+	resp, err = doer.Do(req)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, []string{"probe/1.0", "custom"}, userAgents)
+}
+
+func TestNewHTTPClientIPVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	tableTests := map[string]struct {
+		ipVersion   int
+		expectedErr bool
+	}{
+		"success-auto": {
+			ipVersion: 0,
+		},
+		"success-ipv4": {
+			ipVersion: 4,
+		},
+		"error-ipv6-to-ipv4-server": {
+			ipVersion:   6,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			client, err := newHTTPClient(&config.Config{IPVersion: testCase.ipVersion})
+			assert.NoError(t, err)
+
+			resp, err := client.Get(srv.URL)
+			if testCase.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+		})
+	}
+}
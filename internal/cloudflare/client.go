@@ -0,0 +1,31 @@
+package cloudflare
+
+import (
+	"net/http"
+
+	"github.com/bejaneps/speedtest/internal/config"
+)
+
+// Client is Cloudflare's speed.cloudflare.com client
+type Client struct {
+	conf *config.Config
+	doer HTTPDoer
+}
+
+// HTTPDoer is used for mocking purposes
+//
+//go:generate mockery --name HTTPDoer
+type HTTPDoer interface {
+	// Do sends an HTTP request and returns an HTTP response
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// NewClient is a constructor for Cloudflare client
+func NewClient(conf *config.Config, doer HTTPDoer) *Client {
+	cli := &Client{
+		conf: conf,
+		doer: doer,
+	}
+
+	return cli
+}
@@ -0,0 +1,51 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+// meta is a response of meta endpoint
+// with details about client
+type meta struct {
+	ClientIP       string `json:"clientIp"`
+	ASOrganization string `json:"asOrganization"`
+}
+
+// ClientInfo requests from Cloudflare's speed.cloudflare.com API details about client
+func (c *Client) ClientInfo(ctx context.Context) (measurement.ClientInfo, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.baseURL()+metaPath,
+		nil,
+	)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	info := meta{}
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to json unmarshal response body: %w", err)
+	}
+
+	return measurement.ClientInfo{
+		IP:  info.ClientIP,
+		ISP: info.ASOrganization,
+	}, nil
+}
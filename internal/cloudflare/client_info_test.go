@@ -0,0 +1,73 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bejaneps/speedtest/internal/cloudflare/mocks"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClientInfo(t *testing.T) {
+	tableTests := map[string]struct {
+		setup        func() *mocks.HTTPDoer
+		expectedErr  error
+		expectedInfo measurement.ClientInfo
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				body := `{"hostname":"speed.cloudflare.com","clientIp":"1.2.3.4","httpProtocol":"HTTP/1.1",` +
+					`"asn":64500,"asOrganization":"Example ISP","colo":"AMS","country":"NL","city":"Amsterdam"}`
+
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://speed.cloudflare.com/meta"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(body)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedInfo: measurement.ClientInfo{
+				IP:  "1.2.3.4",
+				ISP: "Example ISP",
+			},
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://speed.cloudflare.com/meta"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(&config.Config{}, doer)
+
+			info, err := cli.ClientInfo(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedInfo, info)
+			}
+		})
+	}
+}
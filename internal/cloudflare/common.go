@@ -0,0 +1,71 @@
+package cloudflare
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+// defaultBaseURL is a base URL of speed.cloudflare.com endpoints
+const defaultBaseURL = "https://speed.cloudflare.com"
+
+const (
+	downloadPath = "/__down"
+	uploadPath   = "/__up"
+	metaPath     = "/meta"
+
+	workload = 4
+)
+
+// baseURL returns base URL of endpoints, which can
+// be replaced with base URL of config
+func (c *Client) baseURL() string {
+	if c.conf.BaseURL != "" {
+		return strings.TrimSuffix(c.conf.BaseURL, "/")
+	}
+
+	return defaultBaseURL
+}
+
+// server returns measurement server, Cloudflare's endpoints are
+// anycast, so there is only one server for every client
+func (c *Client) server() measurement.Server {
+	return measurement.Server{
+		Name:    "Cloudflare",
+		Sponsor: "Cloudflare",
+		URL:     c.baseURL(),
+	}
+}
+
+// serverTiming returns duration of request processing reported by
+// server in Server-Timing header, e.g. "cfRequestDuration;dur=12.3",
+// duration of the first metric that has one is returned, zero is
+// returned if header is missing or malformed.
+//
+// It's only subtracted from ping round trips, download and upload
+// rates include server processing time, which is negligible
+// compared to transfer of their payloads
+func serverTiming(header http.Header) time.Duration {
+	for _, value := range header.Values("Server-Timing") {
+		for _, metric := range strings.Split(value, ",") {
+			for _, param := range strings.Split(metric, ";")[1:] {
+				name, dur, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || name != "dur" {
+					continue
+				}
+
+				ms, err := strconv.ParseFloat(strings.Trim(dur, `"`), 64)
+				if err != nil || ms < 0 {
+					return 0
+				}
+
+				return time.Duration(ms * float64(time.Millisecond))
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,78 @@
+package cloudflare
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseURL(t *testing.T) {
+	tableTests := map[string]struct {
+		baseURL     string
+		expectedURL string
+	}{
+		"success-default": {
+			baseURL:     "",
+			expectedURL: "https://speed.cloudflare.com",
+		},
+		"success-custom": {
+			baseURL:     "http://localhost:8080/",
+			expectedURL: "http://localhost:8080",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			cli := NewClient(&config.Config{BaseURL: testCase.baseURL}, nil)
+			assert.Equal(t, testCase.expectedURL, cli.baseURL())
+		})
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	tableTests := map[string]struct {
+		header           []string
+		expectedDuration time.Duration
+	}{
+		"success-single-metric": {
+			header:           []string{"cfRequestDuration;dur=12.5"},
+			expectedDuration: 12500 * time.Microsecond,
+		},
+		"success-metric-with-description": {
+			header:           []string{`cache;desc="Cache Read";dur=23.2`},
+			expectedDuration: 23200 * time.Microsecond,
+		},
+		"success-first-metric-with-duration": {
+			header:           []string{"miss, db;dur=53", "app;dur=47.2"},
+			expectedDuration: 53 * time.Millisecond,
+		},
+		"success-missing-header": {
+			header:           nil,
+			expectedDuration: 0,
+		},
+		"success-malformed-duration": {
+			header:           []string{"cfRequestDuration;dur=abc"},
+			expectedDuration: 0,
+		},
+		"success-negative-duration": {
+			header:           []string{"cfRequestDuration;dur=-5"},
+			expectedDuration: 0,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			header := http.Header{}
+			for _, value := range testCase.header {
+				header.Add("Server-Timing", value)
+			}
+
+			assert.Equal(t, testCase.expectedDuration, serverTiming(header))
+		})
+	}
+}
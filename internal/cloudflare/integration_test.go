@@ -0,0 +1,87 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// standIn serves endpoints of speed.cloudflare.com
+// with zeroed content and fixed server timing
+func standIn() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(downloadPath, func(w http.ResponseWriter, r *http.Request) {
+		size, err := strconv.ParseInt(r.URL.Query().Get("bytes"), 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Server-Timing", "cfRequestDuration;dur=0.5")
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		_, _ = io.CopyN(w, zeroReader{}, size)
+	})
+	mux.HandleFunc(uploadPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Server-Timing", "cfRequestDuration;dur=0.5")
+	})
+	mux.HandleFunc(metaPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(meta{ClientIP: "127.0.0.1", ASOrganization: "Loopback"})
+	})
+
+	return mux
+}
+
+// zeroReader reads infinite amount of zeros
+type zeroReader struct{}
+
+// Read fills p with zeros
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// TestStandInServer measures against local stand-in of
+// speed.cloudflare.com, so client is tested against
+// real HTTP endpoints
+func TestStandInServer(t *testing.T) {
+	srv := httptest.NewServer(standIn())
+	defer srv.Close()
+
+	cli := NewClient(
+		&config.Config{
+			BaseURL: srv.URL,
+		},
+		&http.Client{Timeout: 10 * time.Second},
+	)
+
+	info, err := cli.ClientInfo(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", info.IP)
+	assert.Equal(t, "Loopback", info.ISP)
+
+	latency, err := cli.MeasureLatency(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, latency.Avg > 0 && latency.Avg < time.Second, latency.Avg)
+
+	download, err := cli.MeasureDownloadTransfer(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(workload*downloadSize), download.Bytes)
+	assert.Equal(t, srv.URL, download.Servers[0].Server.URL)
+	assert.True(t, download.Rate > 0, download.Rate)
+
+	upload, err := cli.MeasureUploadTransfer(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(workload*uploadSize), upload.Bytes)
+	assert.True(t, upload.Rate > 0, upload.Rate)
+}
@@ -0,0 +1,169 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// downloadSize is a size of content requested
+	// by single request of fixed workload test
+	downloadSize = 25_000_000
+
+	// maxDownloadStreams limits amount of concurrent
+	// requests in duration based download test
+	maxDownloadStreams = 16
+)
+
+// downloadSizes are sizes of content requested in duration
+// based download test, from smallest to largest
+var downloadSizes = []int64{100_000, 1_000_000, 10_000_000, 25_000_000, 100_000_000}
+
+// defaultDownloadFunc is a variable to wrap download function
+// for deterministic results
+var defaultDownloadFunc = download
+
+// MeasureDownload measures download speed per second using Cloudflare's speed.cloudflare.com API
+func (c *Client) MeasureDownload(ctx context.Context) (
+	downloadRate measurement.BitRate,
+	err error,
+) {
+	transfer, err := c.MeasureDownloadTransfer(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return transfer.Rate, nil
+}
+
+// MeasureDownloadTransfer measures download speed per second using Cloudflare's
+// speed.cloudflare.com API and returns details of measurement, in case of error
+// results measured so far are returned as well
+func (c *Client) MeasureDownloadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	meter := c.conf.NewMeter()
+	err = c.measureDownload(ctx, c.baseURL(), meter)
+	serverTransfer := measurement.ServerTransfer{
+		Server:   c.server(),
+		Duration: time.Since(start),
+		Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseDownload, c.baseURL(), err),
+	}
+	if err != nil {
+		transfer.Servers = append(transfer.Servers, serverTransfer)
+		transfer.Duration = time.Since(start)
+		return transfer, transfer.Err()
+	}
+
+	serverTransfer.Rate = meter.SampledRate()
+	serverTransfer.Bytes = meter.Bytes()
+	serverTransfer.Spread = meter.SampleSpread()
+	transfer.Servers = append(transfer.Servers, serverTransfer)
+
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers([]measurement.BitRate{serverTransfer.Rate})
+	transfer.Bytes = serverTransfer.Bytes
+	transfer.Duration = time.Since(start)
+
+	return transfer, nil
+}
+
+// measureDownload measures download speed by requesting content from
+// provided url and counting downloaded bytes in meter, if test duration
+// is set, then download test is time-boxed, otherwise it sends n
+// concurrent requests to server
+func (c *Client) measureDownload(ctx context.Context, url string, meter *measurement.Meter) error {
+	if c.conf.TestDuration > 0 {
+		return c.measureDownloadAdaptive(ctx, url, meter)
+	}
+
+	eg := errgroup.Group{}
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseDownload, url)
+
+	for i := 0; i < workload; i++ {
+		eg.Go(func() error {
+			_, err := defaultDownloadFunc(ctx, c.doer, url, downloadSize, meter)
+			return err
+		})
+	}
+	err := eg.Wait()
+	stopProgress()
+
+	return err
+}
+
+// measureDownloadAdaptive measures download speed by requesting provided url
+// in concurrent streams until test duration is over, requests that finish
+// too fast to saturate the link increase content size, and when the largest
+// size is reached, amount of streams
+func (c *Client) measureDownloadAdaptive(ctx context.Context, url string, meter *measurement.Meter) error {
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseDownload, url)
+	defer stopProgress()
+
+	return measurement.Ramp(
+		ctx,
+		c.conf.TestDuration,
+		len(downloadSizes),
+		workload,
+		maxDownloadStreams,
+		func(ctx context.Context, sizeIdx int) error {
+			_, err := defaultDownloadFunc(ctx, c.doer, url, downloadSizes[sizeIdx], meter)
+			return err
+		},
+	)
+}
+
+// download downloads content of provided size from provided url
+// to meter and returns amount of received bytes, failed or
+// truncated body read is returned as error
+func download(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	size int64,
+	meter *measurement.Meter,
+) (int64, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s%s?bytes=%d", url, downloadPath, size),
+		nil,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	n, err := io.Copy(meter, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	if resp.ContentLength > 0 && n < resp.ContentLength {
+		return n, fmt.Errorf("failed to copy response body: %w", io.ErrUnexpectedEOF)
+	}
+
+	return n, nil
+}
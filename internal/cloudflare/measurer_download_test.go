@@ -0,0 +1,172 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/cloudflare/mocks"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureDownload(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+	})
+
+	tableTests := map[string]struct {
+		download          func(ctx context.Context, doer HTTPDoer, url string, size int64, meter *measurement.Meter) (int64, error)
+		expectedErr       error
+		expectedRateRange []measurement.BitRate
+	}{
+		"success-800-mbit": {
+			download: func(ctx context.Context, doer HTTPDoer, url string, size int64, meter *measurement.Meter) (int64, error) {
+				time.Sleep(time.Second)
+				meter.Add(size)
+				return size, nil
+			},
+			expectedErr:       nil,
+			expectedRateRange: []measurement.BitRate{700000000, 810000000},
+		},
+		"error-from-download-fail": {
+			download: func(ctx context.Context, doer HTTPDoer, url string, size int64, meter *measurement.Meter) (int64, error) {
+				return 0, errors.New("random error")
+			},
+			expectedErr:       errors.New("download: transfer failed on https://speed.cloudflare.com: random error"),
+			expectedRateRange: []measurement.BitRate{0, 0},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			defaultDownloadFunc = testCase.download
+
+			cli := NewClient(&config.Config{}, mocks.NewHTTPDoer(t))
+
+			rate, err := cli.MeasureDownload(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+
+				assert.True(t, rate > testCase.expectedRateRange[0] &&
+					rate < testCase.expectedRateRange[1], rate)
+			}
+		})
+	}
+}
+
+func TestMeasureDownloadAdaptive(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+	})
+
+	mu := sync.Mutex{}
+	var sizes []int64
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, size int64, meter *measurement.Meter) (int64, error) {
+		mu.Lock()
+		sizes = append(sizes, size)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		meter.Add(size)
+		return size, nil
+	}
+
+	cli := NewClient(&config.Config{TestDuration: time.Second / 10}, mocks.NewHTTPDoer(t))
+
+	// content size grows, since requests finish
+	// faster than tenth of test duration
+	meter := measurement.NewMeter(0, 0, 0, nil)
+	err := cli.measureDownloadAdaptive(context.Background(), "https://example.com", meter)
+	assert.NoError(t, err)
+	assert.Equal(t, downloadSizes[0], sizes[0])
+	assert.Equal(t, downloadSizes[len(downloadSizes)-1], sizes[len(sizes)-1])
+}
+
+func TestDownload(t *testing.T) {
+	tableTests := map[string]struct {
+		setup         func() *mocks.HTTPDoer
+		expectedErr   error
+		expectedBytes int64
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/__down?bytes=4"
+				})).Return(&http.Response{
+					ContentLength: 4,
+					Body:          io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   nil,
+			expectedBytes: 4,
+		},
+		"error-from-truncated-body": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					ContentLength: 10,
+					Body:          io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to copy response body: unexpected EOF"),
+			expectedBytes: 4,
+		},
+		"error-from-body-read-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					Body: io.NopCloser(io.MultiReader(
+						bytes.NewBufferString("blob"),
+						iotest.ErrReader(errors.New("random error")),
+					)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to copy response body: random error"),
+			expectedBytes: 4,
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to send request: random error"),
+			expectedBytes: 0,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			meter := measurement.NewMeter(0, 0, 0, nil)
+			n, err := download(context.Background(), doer, "https://example.com", 4, meter)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.expectedBytes, n)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+const pingCount = 20
+
+// defaultPingFunc is a variable to wrap ping function
+// for deterministic results
+var defaultPingFunc = ping
+
+// MeasureLatency measures latency and jitter using Cloudflare's speed.cloudflare.com API
+func (c *Client) MeasureLatency(ctx context.Context) (
+	latency measurement.Latency,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	url := c.baseURL()
+
+	// the first ping sets up connection, so its round
+	// trip time includes handshakes and is discarded
+	if _, err := defaultPingFunc(ctx, c.doer, url); err != nil {
+		return measurement.Latency{}, measurement.NewError(measurement.ErrTransfer, measurement.PhaseLatency, url, err)
+	}
+
+	samples := make([]time.Duration, 0, pingCount)
+	start := time.Now()
+	for i := 0; i < pingCount; i++ {
+		rtt, err := defaultPingFunc(ctx, c.doer, url)
+		if err != nil {
+			return measurement.Latency{}, measurement.NewError(measurement.ErrTransfer, measurement.PhaseLatency, url, err)
+		}
+
+		samples = append(samples, rtt)
+		c.conf.ReportLatency(url, rtt, time.Since(start))
+	}
+
+	return measurement.NewLatency(samples), nil
+}
+
+// ping requests empty download from provided url and returns
+// round trip time, time spent by server on processing request
+// is subtracted, so only network time is returned
+func ping(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		url+downloadPath+"?bytes=0",
+		nil,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := doer.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	rtt := time.Since(start)
+
+	// server timing can't be trusted
+	// to be less than round trip time
+	if processing := serverTiming(resp.Header); processing < rtt {
+		rtt -= processing
+	}
+
+	return rtt, nil
+}
@@ -0,0 +1,159 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/cloudflare/mocks"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureLatency(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	tableTests := map[string]struct {
+		ping            func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error)
+		expectedErr     error
+		expectedLatency measurement.Latency
+	}{
+		"success": {
+			ping: func() func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+				rtts := []time.Duration{10, 20}
+				i := 0
+				return func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					rtt := rtts[i%len(rtts)] * time.Millisecond
+					i++
+					return rtt, nil
+				}
+			}(),
+			expectedErr: nil,
+			expectedLatency: measurement.Latency{
+				Min:    10 * time.Millisecond,
+				Avg:    15 * time.Millisecond,
+				Max:    20 * time.Millisecond,
+				Jitter: 10 * time.Millisecond,
+			},
+		},
+		"success-warm-up-discarded": {
+			ping: func() func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+				i := 0
+				return func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					i++
+					if i == 1 {
+						return 500 * time.Millisecond, nil
+					}
+					return 10 * time.Millisecond, nil
+				}
+			}(),
+			expectedErr: nil,
+			expectedLatency: measurement.Latency{
+				Min: 10 * time.Millisecond,
+				Avg: 10 * time.Millisecond,
+				Max: 10 * time.Millisecond,
+			},
+		},
+		"error-from-ping-fail": {
+			ping: func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+				return 0, errors.New("random error")
+			},
+			expectedErr: errors.New("latency: transfer failed on https://speed.cloudflare.com: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			defaultPingFunc = testCase.ping
+
+			cli := NewClient(&config.Config{}, mocks.NewHTTPDoer(t))
+
+			latency, err := cli.MeasureLatency(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedLatency, latency)
+			}
+		})
+	}
+}
+
+func TestPing(t *testing.T) {
+	tableTests := map[string]struct {
+		setup       func() *mocks.HTTPDoer
+		expectedErr error
+		expectedRTT []time.Duration
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.
+					On("Do", mock.MatchedBy(func(req *http.Request) bool {
+						return req.URL.String() == "https://example.com/__down?bytes=0"
+					})).
+					WaitUntil(time.After(time.Second/10)).
+					Return(&http.Response{
+						Body: io.NopCloser(bytes.NewBufferString("")),
+					}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedRTT: []time.Duration{time.Second / 20, time.Second / 5},
+		},
+		"success-server-timing-subtracted": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.
+					On("Do", mock.MatchedBy(func(req *http.Request) bool {
+						return req.URL.String() == "https://example.com/__down?bytes=0"
+					})).
+					WaitUntil(time.After(time.Second/10)).
+					Return(&http.Response{
+						Header: http.Header{"Server-Timing": []string{"cfRequestDuration;dur=80"}},
+						Body:   io.NopCloser(bytes.NewBufferString("")),
+					}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedRTT: []time.Duration{time.Second / 100, time.Second / 10},
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/__down?bytes=0"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			rtt, err := ping(context.Background(), doer, "https://example.com")
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, rtt >= testCase.expectedRTT[0] && rtt < testCase.expectedRTT[1], rtt)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/pkg/random"
+	"golang.org/x/sync/errgroup"
+)
+
+// uploadSize is a size of content posted by single request
+const uploadSize = 10_000_000
+
+// defaultUploadFunc is a variable to wrap upload function
+// for deterministic results
+var defaultUploadFunc = upload
+
+// MeasureUpload measures upload speed per second using Cloudflare's speed.cloudflare.com API
+func (c *Client) MeasureUpload(ctx context.Context) (
+	uploadRate measurement.BitRate,
+	err error,
+) {
+	transfer, err := c.MeasureUploadTransfer(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return transfer.Rate, nil
+}
+
+// MeasureUploadTransfer measures upload speed per second using Cloudflare's
+// speed.cloudflare.com API and returns details of measurement, in case of error
+// results measured so far are returned as well
+func (c *Client) MeasureUploadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	// payload is generated once before meter
+	// is started, so its generation isn't measured
+	content := []byte(random.String(uploadSize))
+
+	start := time.Now()
+	meter := c.conf.NewMeter()
+	err = c.measureUpload(ctx, c.baseURL(), content, meter)
+	serverTransfer := measurement.ServerTransfer{
+		Server:   c.server(),
+		Duration: time.Since(start),
+		Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseUpload, c.baseURL(), err),
+	}
+	if err != nil {
+		transfer.Servers = append(transfer.Servers, serverTransfer)
+		transfer.Duration = time.Since(start)
+		return transfer, transfer.Err()
+	}
+
+	serverTransfer.Rate = meter.SampledRate()
+	serverTransfer.Bytes = meter.Bytes()
+	serverTransfer.Spread = meter.SampleSpread()
+	transfer.Servers = append(transfer.Servers, serverTransfer)
+
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers([]measurement.BitRate{serverTransfer.Rate})
+	transfer.Bytes = serverTransfer.Bytes
+	transfer.Duration = time.Since(start)
+
+	return transfer, nil
+}
+
+// measureUpload measures upload speed by posting content to provided
+// url in concurrent streams and counting uploaded bytes in meter, if
+// test duration is set, then streams repeat requests until it's over,
+// otherwise each stream sends single request
+func (c *Client) measureUpload(
+	ctx context.Context,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) error {
+	eg := errgroup.Group{}
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseUpload, url)
+
+	deadline := time.Now().Add(c.conf.TestDuration)
+	for i := 0; i < workload; i++ {
+		eg.Go(func() error {
+			for {
+				if _, err := defaultUploadFunc(ctx, c.doer, url, content, meter); err != nil {
+					return err
+				}
+				if time.Now().After(deadline) {
+					return nil
+				}
+			}
+		})
+	}
+	err := eg.Wait()
+	stopProgress()
+
+	return err
+}
+
+// upload posts provided content to provided url, counting sent bytes
+// in meter, and returns amount of sent bytes, failed or truncated
+// request body is returned as error, content is only read, so it
+// can be shared by concurrent uploads
+func upload(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) (int64, error) {
+	body := &countingReader{r: meter.Reader(bytes.NewReader(content))}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		url+uploadPath,
+		body,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = int64(len(content))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return body.n, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return body.n, measurement.StatusError(resp.StatusCode)
+	}
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	meter.Stop()
+	if err != nil {
+		return body.n, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	if body.n < req.ContentLength {
+		return body.n, fmt.Errorf("failed to send request body: %w", io.ErrUnexpectedEOF)
+	}
+
+	return body.n, nil
+}
+
+// countingReader counts amount of bytes read from underlying reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read reads from underlying reader and counts read bytes
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,128 @@
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/cloudflare/mocks"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureUpload(t *testing.T) {
+	t.Cleanup(func() {
+		defaultUploadFunc = upload
+	})
+
+	tableTests := map[string]struct {
+		upload            func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error)
+		expectedErr       error
+		expectedRateRange []measurement.BitRate
+	}{
+		"success-320-mbit": {
+			upload: func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
+				time.Sleep(time.Second)
+				meter.Add(uploadSize)
+				return uploadSize, nil
+			},
+			expectedErr:       nil,
+			expectedRateRange: []measurement.BitRate{280000000, 330000000},
+		},
+		"error-from-upload-fail": {
+			upload: func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
+				return 0, errors.New("random error")
+			},
+			expectedErr:       errors.New("upload: transfer failed on https://speed.cloudflare.com: random error"),
+			expectedRateRange: []measurement.BitRate{0, 0},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			defaultUploadFunc = testCase.upload
+
+			cli := NewClient(&config.Config{}, mocks.NewHTTPDoer(t))
+
+			rate, err := cli.MeasureUpload(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+
+				assert.True(t, rate > testCase.expectedRateRange[0] &&
+					rate < testCase.expectedRateRange[1], rate)
+			}
+		})
+	}
+}
+
+func TestUpload(t *testing.T) {
+	tableTests := map[string]struct {
+		setup         func() *mocks.HTTPDoer
+		expectedErr   error
+		expectedBytes int64
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					_, err := io.Copy(io.Discard, req.Body)
+					return err == nil && req.Method == http.MethodPost &&
+						req.URL.String() == "https://example.com/__up"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString("")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   nil,
+			expectedBytes: uploadSize,
+		},
+		"error-from-unsent-body": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString("")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to send request body: unexpected EOF"),
+			expectedBytes: 0,
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to send request: random error"),
+			expectedBytes: 0,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			meter := measurement.NewMeter(0, 0, 0, nil)
+			n, err := upload(context.Background(), doer, "https://example.com", make([]byte, uploadSize), meter)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.expectedBytes, n)
+		})
+	}
+}
@@ -1,10 +1,226 @@
 package config
 
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+const defaultProgressInterval = 500 * time.Millisecond
+
+// FailurePolicy defines how measurement reacts
+// to servers that failed to be measured
+type FailurePolicy int
+
+const (
+	// FailFast fails measurement on first failed server
+	FailFast FailurePolicy = iota
+
+	// TolerateFailures fails measurement only if more
+	// than MaxFailures servers failed
+	TolerateFailures
+
+	// BestEffort fails measurement only if all servers failed
+	BestEffort
+)
+
+// HTTPDoer sends HTTP requests, *http.Client implements it
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 type Config struct {
 	ServerCount int
 	Token       string
+
+	// ServerListURL replaces URL of server list
+	// of measurement tool, e.g. with self-hosted one
+	ServerListURL string
+
+	// BaseURL replaces base URL of endpoints of
+	// measurement tool, e.g. with local stand-in
+	BaseURL string
+
+	FailurePolicy FailurePolicy
+	MaxFailures   int
+
+	// TestDuration time-boxes measurement against
+	// each server, zero means fixed workload
+	TestDuration time.Duration
+
+	// WarmupDuration and WarmupBytes define warmup period
+	// of transfer, which is excluded from transfer rate
+	WarmupDuration time.Duration
+	WarmupBytes    int64
+
+	// SampleInterval makes transfer rate to be sampled
+	// every interval, zero means no sampling
+	SampleInterval time.Duration
+
+	// Progress is called every ProgressInterval
+	// with intermediate state of measurement
+	Progress         func(measurement.Progress)
+	ProgressInterval time.Duration
+
+	// Phases are phases of measurement run
+	// by full test, empty means all phases
+	Phases []measurement.Phase
+
+	// ServerAggregation combines rates of servers and
+	// SampleAggregation combines rates sampled during
+	// transfer, nil means arithmetic mean
+	ServerAggregation measurement.Aggregation
+	SampleAggregation measurement.Aggregation
+
+	// HTTPClient sends requests of measurement, if it's
+	// nil, then client is built from transport settings
+	HTTPClient HTTPDoer
+
+	// Proxy, SourceIP, Interface and TLSConfig are transport
+	// settings of built client, Interface is used to find
+	// source IP if SourceIP isn't set
+	Proxy     string
+	SourceIP  string
+	Interface string
+	TLSConfig *tls.Config
+
+	// UserAgent is set to requests that don't have one
+	UserAgent string
+
+	// IPVersion restricts address family of connections
+	// of built client to IPv4 or IPv6, zero means any
+	IPVersion int
+
+	// RequestTimeout limits duration of a single request and
+	// Timeout limits duration of a whole measurement
+	RequestTimeout time.Duration
+	Timeout        time.Duration
 }
 
 // Option is an optional functionality for
 // any speedtest client
 type Option func(*Config)
+
+// Tolerates reports whether provided amount of failed servers
+// out of total servers is allowed by failure policy,
+// failure of all servers is never allowed
+func (c *Config) Tolerates(failures, total int) bool {
+	if failures == 0 {
+		return true
+	}
+	if failures >= total {
+		return false
+	}
+
+	switch c.FailurePolicy {
+	case TolerateFailures:
+		return failures <= c.MaxFailures
+	case BestEffort:
+		return true
+	}
+
+	return false
+}
+
+// Network returns network restricted to configured
+// address family, e.g. "tcp4" for "tcp" and IPv4
+func (c *Config) Network(network string) string {
+	switch c.IPVersion {
+	case 4, 6:
+		return network + strconv.Itoa(c.IPVersion)
+	}
+
+	return network
+}
+
+// WithTimeout returns context limited by timeout of
+// measurement, context isn't limited if timeout isn't set
+func (c *Config) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// RunsPhase reports whether provided phase
+// should be run by full test
+func (c *Config) RunsPhase(phase measurement.Phase) bool {
+	if len(c.Phases) == 0 {
+		return true
+	}
+
+	for _, p := range c.Phases {
+		if p == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewMeter creates meter for transfer against single server
+func (c *Config) NewMeter() *measurement.Meter {
+	return measurement.NewMeter(
+		c.WarmupDuration,
+		c.WarmupBytes,
+		c.SampleInterval,
+		c.SampleAggregation,
+	)
+}
+
+// AggregateServers combines rates of succeeded servers into single
+// rate using server aggregation and calculates their spread
+func (c *Config) AggregateServers(rates []measurement.BitRate) (measurement.BitRate, measurement.Spread) {
+	aggregation := c.ServerAggregation
+	if aggregation == nil {
+		aggregation = measurement.Mean
+	}
+
+	return aggregation(rates), measurement.NewSpread(rates)
+}
+
+// WatchProgress starts reporting progress of transfer counted by meter,
+// if progress callback is set, returned function stops reporting
+func (c *Config) WatchProgress(
+	meter *measurement.Meter,
+	phase measurement.Phase,
+	server string,
+) (stop func()) {
+	if c.Progress == nil {
+		return func() {}
+	}
+
+	interval := c.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	return meter.Report(
+		interval,
+		measurement.Progress{
+			Phase:  phase,
+			Server: server,
+		},
+		c.Progress,
+	)
+}
+
+// ReportLatency reports round trip time of a single ping
+// to provided server, if progress callback is set
+func (c *Config) ReportLatency(server string, rtt, elapsed time.Duration) {
+	if c.Progress == nil {
+		return
+	}
+
+	c.Progress(measurement.Progress{
+		Phase:   measurement.PhaseLatency,
+		Server:  server,
+		Latency: rtt,
+		Elapsed: elapsed,
+	})
+}
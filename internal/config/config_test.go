@@ -0,0 +1,172 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTolerates(t *testing.T) {
+	tableTests := map[string]struct {
+		conf     Config
+		failures int
+		total    int
+		expected bool
+	}{
+		"fail-fast-no-failures": {
+			conf:     Config{FailurePolicy: FailFast},
+			failures: 0,
+			total:    10,
+			expected: true,
+		},
+		"fail-fast-one-failure": {
+			conf:     Config{FailurePolicy: FailFast},
+			failures: 1,
+			total:    10,
+			expected: false,
+		},
+		"tolerate-failures-within-limit": {
+			conf:     Config{FailurePolicy: TolerateFailures, MaxFailures: 2},
+			failures: 2,
+			total:    10,
+			expected: true,
+		},
+		"tolerate-failures-over-limit": {
+			conf:     Config{FailurePolicy: TolerateFailures, MaxFailures: 2},
+			failures: 3,
+			total:    10,
+			expected: false,
+		},
+		"tolerate-failures-all-failed": {
+			conf:     Config{FailurePolicy: TolerateFailures, MaxFailures: 2},
+			failures: 2,
+			total:    2,
+			expected: false,
+		},
+		"best-effort-some-failed": {
+			conf:     Config{FailurePolicy: BestEffort},
+			failures: 9,
+			total:    10,
+			expected: true,
+		},
+		"best-effort-all-failed": {
+			conf:     Config{FailurePolicy: BestEffort},
+			failures: 10,
+			total:    10,
+			expected: false,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, testCase.conf.Tolerates(testCase.failures, testCase.total))
+		})
+	}
+}
+
+func TestAggregateServers(t *testing.T) {
+	rates := []measurement.BitRate{10, 20, 90}
+
+	tableTests := map[string]struct {
+		conf         Config
+		expectedRate measurement.BitRate
+	}{
+		"success-default-mean": {
+			conf:         Config{},
+			expectedRate: 40,
+		},
+		"success-median": {
+			conf:         Config{ServerAggregation: measurement.Median},
+			expectedRate: 20,
+		},
+		"success-maximum": {
+			conf:         Config{ServerAggregation: measurement.Maximum},
+			expectedRate: 90,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			rate, spread := testCase.conf.AggregateServers(rates)
+			assert.Equal(t, testCase.expectedRate, rate)
+			assert.Equal(t, measurement.NewSpread(rates), spread)
+		})
+	}
+}
+
+func TestWatchProgress(t *testing.T) {
+	var reports []measurement.Progress
+	conf := Config{
+		Progress: func(p measurement.Progress) {
+			reports = append(reports, p)
+		},
+		ProgressInterval: time.Hour,
+	}
+
+	meter := measurement.NewMeter(0, 0, 0, nil)
+	stop := conf.WatchProgress(meter, measurement.PhaseUpload, "https://example.com")
+	meter.Add(100)
+	stop()
+
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, measurement.PhaseUpload, reports[0].Phase)
+	assert.Equal(t, "https://example.com", reports[0].Server)
+	assert.Equal(t, int64(100), reports[0].Bytes)
+}
+
+func TestWatchProgressWithoutCallback(t *testing.T) {
+	conf := Config{}
+
+	stop := conf.WatchProgress(measurement.NewMeter(0, 0, 0, nil), measurement.PhaseUpload, "https://example.com")
+	stop()
+}
+
+func TestWithTimeout(t *testing.T) {
+	conf := Config{Timeout: time.Minute}
+	ctx, cancel := conf.WithTimeout(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	conf = Config{}
+	ctx, cancel = conf.WithTimeout(context.Background())
+	defer cancel()
+
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestNetwork(t *testing.T) {
+	tableTests := map[string]struct {
+		ipVersion       int
+		expectedNetwork string
+	}{
+		"success-auto": {
+			ipVersion:       0,
+			expectedNetwork: "tcp",
+		},
+		"success-ipv4": {
+			ipVersion:       4,
+			expectedNetwork: "tcp4",
+		},
+		"success-ipv6": {
+			ipVersion:       6,
+			expectedNetwork: "tcp6",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			conf := Config{IPVersion: testCase.ipVersion}
+			assert.Equal(t, testCase.expectedNetwork, conf.Network("tcp"))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package librespeed
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/bejaneps/speedtest/internal/config"
+)
+
+// Client is LibreSpeed client
+type Client struct {
+	conf *config.Config
+	doer HTTPDoer
+
+	// servers are selected once and reused by all
+	// measurement phases until any of them fails
+	serversMu sync.Mutex
+	servers   []serverDetails
+}
+
+// HTTPDoer is used for mocking purposes
+//
+//go:generate mockery --name HTTPDoer
+type HTTPDoer interface {
+	// Do sends an HTTP request and returns an HTTP response
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// NewClient is a constructor for LibreSpeed client
+func NewClient(conf *config.Config, doer HTTPDoer) *Client {
+	// at least one server is
+	// needed for measurement
+	if conf.ServerCount == 0 {
+		conf.ServerCount = 1
+	}
+
+	cli := &Client{
+		conf: conf,
+		doer: doer,
+	}
+
+	return cli
+}
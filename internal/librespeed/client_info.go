@@ -0,0 +1,72 @@
+package librespeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+// ipInfo is a response of getIP.php, processed string
+// is formatted as "IP - ISP, country (distance)"
+type ipInfo struct {
+	ProcessedString string `json:"processedString"`
+}
+
+// ClientInfo requests from the closest LibreSpeed server details about client
+func (c *Client) ClientInfo(ctx context.Context) (measurement.ClientInfo, error) {
+	servers, err := c.selectServers(ctx)
+	if err != nil {
+		return measurement.ClientInfo{}, err
+	}
+	server := servers[0]
+
+	if server.GetIPURL == "" {
+		return measurement.ClientInfo{}, errors.New("server doesn't provide client details")
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		server.endpoint(server.GetIPURL)+"?isp=true",
+		nil,
+	)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	info := ipInfo{}
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to json unmarshal response body: %w", err)
+	}
+
+	return parseProcessedString(info.ProcessedString), nil
+}
+
+// parseProcessedString parses client details from processed string
+// of getIP.php, e.g. "1.2.3.4 - Example ISP, NL (12 km)"
+func parseProcessedString(s string) measurement.ClientInfo {
+	ip, rest, _ := strings.Cut(s, " - ")
+	isp, _, _ := strings.Cut(rest, ", ")
+	isp, _, _ = strings.Cut(isp, " (")
+
+	return measurement.ClientInfo{
+		IP:  strings.TrimSpace(ip),
+		ISP: strings.TrimSpace(isp),
+	}
+}
@@ -0,0 +1,127 @@
+package librespeed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/librespeed/mocks"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClientInfo(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
+	tableTests := map[string]struct {
+		setup        func() *mocks.HTTPDoer
+		expectedErr  error
+		expectedInfo measurement.ClientInfo
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://librespeed.org/backend-servers/servers.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[{"server":"//example.com/","getIpURL":"getIP.php"}]`)),
+				}, nil)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/getIP.php?isp=true"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(
+						`{"processedString":"1.2.3.4 - Example ISP, NL (12 km)","rawIspInfo":""}`,
+					)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedInfo: measurement.ClientInfo{
+				IP:  "1.2.3.4",
+				ISP: "Example ISP",
+			},
+		},
+		"error-from-missing-endpoint": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[{"server":"//example.com/"}]`)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: errors.New("server doesn't provide client details"),
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("server discovery failed on https://librespeed.org/backend-servers/servers.php: failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(
+				&config.Config{
+					ServerCount: 1,
+				},
+				doer,
+			)
+
+			info, err := cli.ClientInfo(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedInfo, info)
+			}
+		})
+	}
+}
+
+func TestParseProcessedString(t *testing.T) {
+	tableTests := map[string]struct {
+		processed    string
+		expectedInfo measurement.ClientInfo
+	}{
+		"success-full": {
+			processed:    "1.2.3.4 - Example ISP, NL (12 km)",
+			expectedInfo: measurement.ClientInfo{IP: "1.2.3.4", ISP: "Example ISP"},
+		},
+		"success-without-country": {
+			processed:    "1.2.3.4 - Example ISP (12 km)",
+			expectedInfo: measurement.ClientInfo{IP: "1.2.3.4", ISP: "Example ISP"},
+		},
+		"success-ip-only": {
+			processed:    "::1",
+			expectedInfo: measurement.ClientInfo{IP: "::1"},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedInfo, parseProcessedString(testCase.processed))
+		})
+	}
+}
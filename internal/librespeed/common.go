@@ -0,0 +1,219 @@
+package librespeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"golang.org/x/sync/errgroup"
+)
+
+// serverListURL is a list of public LibreSpeed servers
+const serverListURL = "https://librespeed.org/backend-servers/servers.php"
+
+const (
+	workload = 4
+
+	candidatePingCount = 3
+)
+
+// serverDetails is an entry of LibreSpeed server list, URLs
+// of endpoints are relative to server URL, which may omit scheme
+type serverDetails struct {
+	ID          json.Number `json:"id"`
+	Name        string      `json:"name"`
+	Server      string      `json:"server"`
+	DownloadURL string      `json:"dlURL"`
+	UploadURL   string      `json:"ulURL"`
+	PingURL     string      `json:"pingURL"`
+	GetIPURL    string      `json:"getIpURL"`
+	SponsorName string      `json:"sponsorName"`
+
+	// latency is measured by client
+	// while selecting closest servers
+	latency time.Duration
+}
+
+// endpoint returns absolute URL of server endpoint
+func (s serverDetails) endpoint(path string) string {
+	base := s.Server
+	if strings.HasPrefix(base, "//") {
+		base = "https:" + base
+	}
+
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// server converts server details to measurement server
+func (s serverDetails) server() measurement.Server {
+	return measurement.Server{
+		ID:      s.ID.String(),
+		Name:    s.Name,
+		Sponsor: s.SponsorName,
+		URL:     s.endpoint(""),
+		Latency: s.latency,
+	}
+}
+
+// selectServers returns servers with the lowest latency, they are
+// selected on the first call and reused by the following ones, until
+// they're reset
+func (c *Client) selectServers(ctx context.Context) ([]serverDetails, error) {
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+
+	if c.servers != nil {
+		return c.servers, nil
+	}
+
+	servers, err := c.rankServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.servers = servers
+
+	return servers, nil
+}
+
+// resetServers discards selected servers, so failed server
+// isn't reused and servers are selected again by next call
+func (c *Client) resetServers() {
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+
+	c.servers = nil
+}
+
+// rankServers requests candidate servers from server list,
+// pings each of them and returns servers with the lowest latency
+func (c *Client) rankServers(ctx context.Context) ([]serverDetails, error) {
+	candidates, err := c.getServersDetails(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eg := errgroup.Group{}
+	mu := sync.Mutex{}
+	servers := make([]serverDetails, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		candidate := candidate
+
+		eg.Go(func() error {
+			var rttSum time.Duration
+			for i := 0; i < candidatePingCount; i++ {
+				rtt, err := defaultPingFunc(ctx, c.doer, candidate.endpoint(candidate.PingURL))
+				if err != nil {
+					// unreachable server is
+					// just not selected
+					return nil
+				}
+				rttSum += rtt
+			}
+			candidate.latency = rttSum / candidatePingCount
+
+			mu.Lock()
+			servers = append(servers, candidate)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	if len(servers) == 0 {
+		return nil, measurement.NewError(
+			measurement.ErrNoServers, "", "",
+			errors.New("failed to reach any of candidate servers"),
+		)
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].latency < servers[j].latency
+	})
+	if len(servers) > c.conf.ServerCount {
+		servers = servers[:c.conf.ServerCount]
+	}
+
+	return servers, nil
+}
+
+// getServersDetails requests list of candidate servers for running
+// download and upload tests, self-hosted instances can provide
+// their own list with server list URL of config, failures are
+// returned as server discovery errors
+func (c *Client) getServersDetails(ctx context.Context) ([]serverDetails, error) {
+	listURL := serverListURL
+	if c.conf.ServerListURL != "" {
+		listURL = c.conf.ServerListURL
+	}
+
+	servers, err := c.requestServersDetails(ctx, listURL)
+	if err != nil {
+		return nil, measurement.NewError(measurement.ErrServerDiscovery, "", listURL, err)
+	}
+	if len(servers) == 0 {
+		return nil, measurement.NewError(measurement.ErrNoServers, "", listURL, errors.New("server list is empty"))
+	}
+
+	return servers, nil
+}
+
+// requestServersDetails requests list of candidate
+// servers from provided url
+func (c *Client) requestServersDetails(ctx context.Context, listURL string) ([]serverDetails, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		listURL,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, measurement.StatusError(resp.StatusCode)
+	}
+
+	var servers []serverDetails
+	err = json.NewDecoder(resp.Body).Decode(&servers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to json unmarshal response body: %w", err)
+	}
+
+	return servers, nil
+}
+
+// Servers returns servers which would be used for measurement,
+// sorted by latency
+func (c *Client) Servers(ctx context.Context) ([]measurement.Server, error) {
+	details, err := c.selectServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make([]measurement.Server, 0, len(details))
+	for _, d := range details {
+		servers = append(servers, d.server())
+	}
+
+	return servers, nil
+}
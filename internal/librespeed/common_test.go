@@ -0,0 +1,319 @@
+package librespeed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/librespeed/mocks"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testServerList = `[{"id":1,"name":"Amsterdam","server":"//ams.example.com/","dlURL":"garbage.php",` +
+	`"ulURL":"empty.php","pingURL":"empty.php","getIpURL":"getIP.php","sponsorName":"Example"},` +
+	`{"id":2,"name":"Berlin","server":"https://ber.example.com/speedtest/","dlURL":"backend/garbage.php",` +
+	`"ulURL":"backend/empty.php","pingURL":"backend/empty.php","getIpURL":"backend/getIP.php","sponsorName":""}]`
+
+func TestEndpoint(t *testing.T) {
+	tableTests := map[string]struct {
+		server      serverDetails
+		path        string
+		expectedURL string
+	}{
+		"success-protocol-relative": {
+			server:      serverDetails{Server: "//ams.example.com/"},
+			path:        "garbage.php",
+			expectedURL: "https://ams.example.com/garbage.php",
+		},
+		"success-nested-path": {
+			server:      serverDetails{Server: "https://ber.example.com/speedtest"},
+			path:        "/backend/empty.php",
+			expectedURL: "https://ber.example.com/speedtest/backend/empty.php",
+		},
+		"success-plain-http": {
+			server:      serverDetails{Server: "http://localhost:8080/"},
+			path:        "empty.php",
+			expectedURL: "http://localhost:8080/empty.php",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedURL, testCase.server.endpoint(testCase.path))
+		})
+	}
+}
+
+func TestGetServersDetails(t *testing.T) {
+	tableTests := map[string]struct {
+		setup           func() *mocks.HTTPDoer
+		serverListURL   string
+		expectedErr     error
+		expectedServers []serverDetails
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://librespeed.org/backend-servers/servers.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(testServerList)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedServers: []serverDetails{
+				{
+					ID:          "1",
+					Name:        "Amsterdam",
+					Server:      "//ams.example.com/",
+					DownloadURL: "garbage.php",
+					UploadURL:   "empty.php",
+					PingURL:     "empty.php",
+					GetIPURL:    "getIP.php",
+					SponsorName: "Example",
+				},
+				{
+					ID:          "2",
+					Name:        "Berlin",
+					Server:      "https://ber.example.com/speedtest/",
+					DownloadURL: "backend/garbage.php",
+					UploadURL:   "backend/empty.php",
+					PingURL:     "backend/empty.php",
+					GetIPURL:    "backend/getIP.php",
+				},
+			},
+		},
+		"success-custom-server-list": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "http://localhost:8080/servers.json"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[{"id":7,"server":"http://localhost:8080/"}]`)),
+				}, nil)
+
+				return mockDoer
+			},
+			serverListURL: "http://localhost:8080/servers.json",
+			expectedErr:   nil,
+			expectedServers: []serverDetails{
+				{
+					ID:     "7",
+					Server: "http://localhost:8080/",
+				},
+			},
+		},
+		"error-from-empty-list": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[]`)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: errors.New("no servers available on https://librespeed.org/backend-servers/servers.php: server list is empty"),
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("server discovery failed on https://librespeed.org/backend-servers/servers.php: failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(
+				&config.Config{
+					ServerListURL: testCase.serverListURL,
+				},
+				doer,
+			)
+
+			servers, err := cli.getServersDetails(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedServers, servers)
+			}
+		})
+	}
+}
+
+func TestSelectServers(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	tableTests := map[string]struct {
+		ping            func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error)
+		serverCount     int
+		expectedErr     error
+		expectedServers []string
+	}{
+		"success-closest-server": {
+			ping: func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+				if url == "https://ams.example.com/empty.php" {
+					return 30 * time.Millisecond, nil
+				}
+				return 10 * time.Millisecond, nil
+			},
+			serverCount:     1,
+			expectedErr:     nil,
+			expectedServers: []string{"Berlin"},
+		},
+		"success-unreachable-server-skipped": {
+			ping: func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+				if url == "https://ber.example.com/speedtest/backend/empty.php" {
+					return 0, errors.New("random error")
+				}
+				return 10 * time.Millisecond, nil
+			},
+			serverCount:     1,
+			expectedErr:     nil,
+			expectedServers: []string{"Amsterdam"},
+		},
+		"success-all-servers-ranked": {
+			ping: func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+				if url == "https://ams.example.com/empty.php" {
+					return 30 * time.Millisecond, nil
+				}
+				return 10 * time.Millisecond, nil
+			},
+			serverCount:     2,
+			expectedErr:     nil,
+			expectedServers: []string{"Berlin", "Amsterdam"},
+		},
+		"error-from-unreachable-servers": {
+			ping: func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+				return 0, errors.New("random error")
+			},
+			serverCount: 1,
+			expectedErr: errors.New("no servers available: failed to reach any of candidate servers"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			mockDoer := new(mocks.HTTPDoer)
+			mockDoer.On("Do", mock.Anything).Return(&http.Response{
+				Body: io.NopCloser(bytes.NewBufferString(testServerList)),
+			}, nil)
+			defaultPingFunc = testCase.ping
+
+			cli := NewClient(
+				&config.Config{
+					ServerCount: testCase.serverCount,
+				},
+				mockDoer,
+			)
+
+			servers, err := cli.selectServers(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+
+				names := make([]string, 0, len(servers))
+				for _, server := range servers {
+					names = append(names, server.Name)
+				}
+				assert.Equal(t, testCase.expectedServers, names)
+			}
+		})
+	}
+}
+
+func TestSelectServersCached(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	mockDoer := mocks.NewHTTPDoer(t)
+	mockDoer.On("Do", mock.Anything).Return(&http.Response{
+		Body: io.NopCloser(bytes.NewBufferString(testServerList)),
+	}, nil).Once()
+
+	mu := sync.Mutex{}
+	pings := 0
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		mu.Lock()
+		pings++
+		mu.Unlock()
+		return time.Millisecond, nil
+	}
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount: 1,
+		},
+		mockDoer,
+	)
+
+	first, err := cli.selectServers(context.Background())
+	assert.NoError(t, err)
+
+	second, err := cli.Servers(context.Background())
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 1)
+	assert.Equal(t, first[0].server(), second[0])
+	assert.Equal(t, 2*candidatePingCount, pings)
+}
+
+func TestSelectServersResetAfterFailure(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+		defaultPingFunc = ping
+	})
+
+	// server list is requested again, since
+	// selected server failed download phase
+	mockDoer := mocks.NewHTTPDoer(t)
+	for i := 0; i < 2; i++ {
+		mockDoer.On("Do", mock.Anything).Return(&http.Response{
+			Body: io.NopCloser(bytes.NewBufferString(testServerList)),
+		}, nil).Once()
+	}
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, chunks int, meter *measurement.Meter) (int64, error) {
+		return 0, errors.New("random error")
+	}
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount: 1,
+		},
+		mockDoer,
+	)
+
+	_, err := cli.MeasureDownloadTransfer(context.Background())
+	assert.Error(t, err)
+
+	servers, err := cli.selectServers(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, servers, 1)
+}
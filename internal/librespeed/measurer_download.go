@@ -0,0 +1,191 @@
+package librespeed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// downloadChunks is an amount of 1MB chunks
+	// requested from garbage.php by single request
+	downloadChunks = 25
+
+	// maxDownloadStreams limits amount of concurrent
+	// requests in duration based download test
+	maxDownloadStreams = 16
+)
+
+// downloadChunkCounts are amounts of 1MB chunks requested in duration
+// based download test, from smallest to largest
+var downloadChunkCounts = []int{1, 2, 5, 10, 25, 50, 100}
+
+// defaultDownloadFunc is a variable to wrap download function
+// for deterministic results
+var defaultDownloadFunc = download
+
+// MeasureDownload measures download speed per second using LibreSpeed servers
+func (c *Client) MeasureDownload(ctx context.Context) (
+	downloadRate measurement.BitRate,
+	err error,
+) {
+	transfer, err := c.MeasureDownloadTransfer(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return transfer.Rate, nil
+}
+
+// MeasureDownloadTransfer measures download speed per second using LibreSpeed servers
+// and returns details of measurement including per server results, failed servers
+// are handled according to failure policy, in case of error results measured
+// so far are returned as well
+func (c *Client) MeasureDownloadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.selectServers(ctx)
+	if err != nil {
+		return measurement.Transfer{}, err
+	}
+
+	// for each server calculate download speeds
+	// and aggregate rates of succeeded ones
+	start := time.Now()
+	rates := make([]measurement.BitRate, 0, len(servers))
+	failures := 0
+	for _, server := range servers {
+		url := server.endpoint(server.DownloadURL)
+
+		serverStart := time.Now()
+		meter := c.conf.NewMeter()
+		err := c.measureDownload(ctx, url, meter)
+		serverTransfer := measurement.ServerTransfer{
+			Server:   server.server(),
+			Duration: time.Since(serverStart),
+			Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseDownload, url, err),
+		}
+		if err != nil {
+			transfer.Servers = append(transfer.Servers, serverTransfer)
+			failures++
+			c.resetServers()
+			if !c.conf.Tolerates(failures, len(servers)) {
+				transfer.Duration = time.Since(start)
+				return transfer, transfer.Err()
+			}
+			continue
+		}
+
+		serverTransfer.Rate = meter.SampledRate()
+		serverTransfer.Bytes = meter.Bytes()
+		serverTransfer.Spread = meter.SampleSpread()
+		transfer.Servers = append(transfer.Servers, serverTransfer)
+
+		rates = append(rates, serverTransfer.Rate)
+		transfer.Bytes += serverTransfer.Bytes
+	}
+
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers(rates)
+	transfer.Duration = time.Since(start)
+
+	return transfer, nil
+}
+
+// measureDownload measures download speed by requesting garbage from
+// provided url in concurrent streams and counting downloaded bytes in
+// meter, if test duration is set, then download test is time-boxed,
+// otherwise each stream sends single request
+func (c *Client) measureDownload(ctx context.Context, url string, meter *measurement.Meter) error {
+	if c.conf.TestDuration > 0 {
+		return c.measureDownloadAdaptive(ctx, url, meter)
+	}
+
+	eg := errgroup.Group{}
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseDownload, url)
+
+	for i := 0; i < workload; i++ {
+		eg.Go(func() error {
+			_, err := defaultDownloadFunc(ctx, c.doer, url, downloadChunks, meter)
+			return err
+		})
+	}
+	err := eg.Wait()
+	stopProgress()
+
+	return err
+}
+
+// measureDownloadAdaptive measures download speed by requesting garbage
+// from provided url in concurrent streams until test duration is over,
+// requests that finish too fast to saturate the link increase amount of
+// chunks, and when the largest amount is reached, amount of streams
+func (c *Client) measureDownloadAdaptive(ctx context.Context, url string, meter *measurement.Meter) error {
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseDownload, url)
+	defer stopProgress()
+
+	return measurement.Ramp(
+		ctx,
+		c.conf.TestDuration,
+		len(downloadChunkCounts),
+		workload,
+		maxDownloadStreams,
+		func(ctx context.Context, sizeIdx int) error {
+			_, err := defaultDownloadFunc(ctx, c.doer, url, downloadChunkCounts[sizeIdx], meter)
+			return err
+		},
+	)
+}
+
+// download downloads provided amount of 1MB garbage chunks from provided
+// url to meter and returns amount of received bytes, failed or truncated
+// body read is returned as error
+func download(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	chunks int,
+	meter *measurement.Meter,
+) (int64, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s?ckSize=%d", url, chunks),
+		nil,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	n, err := io.Copy(meter, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	if resp.ContentLength > 0 && n < resp.ContentLength {
+		return n, fmt.Errorf("failed to copy response body: %w", io.ErrUnexpectedEOF)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,204 @@
+package librespeed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/librespeed/mocks"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureDownload(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+		defaultPingFunc = ping
+	})
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
+	tableTests := map[string]struct {
+		setup             func() *mocks.HTTPDoer
+		expectedErr       error
+		expectedRateRange []measurement.BitRate
+	}{
+		"success-80-mbit": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://librespeed.org/backend-servers/servers.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[{"server":"//example.com/","dlURL":"garbage.php"}]`)),
+				}, nil)
+
+				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, chunks int, meter *measurement.Meter) (int64, error) {
+					time.Sleep(time.Second / 2)
+					meter.Add(1_250_000)
+					return 1_250_000, nil
+				}
+
+				return mockDoer
+			},
+			expectedErr:       nil,
+			expectedRateRange: []measurement.BitRate{70000000, 81000000},
+		},
+		"error-from-download-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://librespeed.org/backend-servers/servers.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[{"server":"//example.com/","dlURL":"garbage.php"}]`)),
+				}, nil)
+
+				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, chunks int, meter *measurement.Meter) (int64, error) {
+					return 0, errors.New("random error")
+				}
+
+				return mockDoer
+			},
+			expectedErr:       errors.New("download: transfer failed on https://example.com/garbage.php: random error"),
+			expectedRateRange: []measurement.BitRate{0, 0},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(
+				&config.Config{
+					ServerCount: 1,
+				},
+				doer,
+			)
+
+			rate, err := cli.MeasureDownload(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+
+				assert.True(t, rate > testCase.expectedRateRange[0] &&
+					rate < testCase.expectedRateRange[1], rate)
+			}
+		})
+	}
+}
+
+func TestMeasureDownloadAdaptive(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+	})
+
+	mu := sync.Mutex{}
+	var counts []int
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, chunks int, meter *measurement.Meter) (int64, error) {
+		mu.Lock()
+		counts = append(counts, chunks)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		meter.Add(int64(chunks))
+		return int64(chunks), nil
+	}
+
+	cli := NewClient(&config.Config{TestDuration: time.Second / 10}, mocks.NewHTTPDoer(t))
+
+	// amount of chunks grows, since requests finish
+	// faster than tenth of test duration
+	meter := measurement.NewMeter(0, 0, 0, nil)
+	err := cli.measureDownloadAdaptive(context.Background(), "https://example.com/garbage.php", meter)
+	assert.NoError(t, err)
+	assert.Equal(t, downloadChunkCounts[0], counts[0])
+	assert.Equal(t, downloadChunkCounts[len(downloadChunkCounts)-1], counts[len(counts)-1])
+}
+
+func TestDownload(t *testing.T) {
+	tableTests := map[string]struct {
+		setup         func() *mocks.HTTPDoer
+		expectedErr   error
+		expectedBytes int64
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/garbage.php?ckSize=25"
+				})).Return(&http.Response{
+					ContentLength: 4,
+					Body:          io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   nil,
+			expectedBytes: 4,
+		},
+		"error-from-truncated-body": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					ContentLength: 10,
+					Body:          io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to copy response body: unexpected EOF"),
+			expectedBytes: 4,
+		},
+		"error-from-body-read-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					Body: io.NopCloser(io.MultiReader(
+						bytes.NewBufferString("blob"),
+						iotest.ErrReader(errors.New("random error")),
+					)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to copy response body: random error"),
+			expectedBytes: 4,
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to send request: random error"),
+			expectedBytes: 0,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			meter := measurement.NewMeter(0, 0, 0, nil)
+			n, err := download(context.Background(), doer, "https://example.com/garbage.php", downloadChunks, meter)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.expectedBytes, n)
+		})
+	}
+}
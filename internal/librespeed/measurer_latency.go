@@ -0,0 +1,106 @@
+package librespeed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+const pingCount = 10
+
+// defaultPingFunc is a variable to wrap ping function
+// for deterministic results
+var defaultPingFunc = ping
+
+// MeasureLatency measures latency and jitter using LibreSpeed servers
+func (c *Client) MeasureLatency(ctx context.Context) (
+	latency measurement.Latency,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.selectServers(ctx)
+	if err != nil {
+		return measurement.Latency{}, err
+	}
+
+	// for each server calculate latency
+	// and take average numbers of succeeded ones
+	latencies := make([]measurement.Latency, 0, len(servers))
+	var errs []error
+	for _, server := range servers {
+		latency, err := c.measureLatency(ctx, server.endpoint(server.PingURL))
+		if err != nil {
+			errs = append(errs, err)
+			c.resetServers()
+			if !c.conf.Tolerates(len(errs), len(servers)) {
+				return measurement.Latency{}, measurement.ServersError(errs, len(servers))
+			}
+			continue
+		}
+
+		latencies = append(latencies, latency)
+	}
+
+	return measurement.AverageLatency(latencies), nil
+}
+
+// measureLatency sends n sequential pings to provided url
+// and calculates latency statistics from round trip times
+func (c *Client) measureLatency(ctx context.Context, url string) (measurement.Latency, error) {
+	samples := make([]time.Duration, 0, pingCount)
+	start := time.Now()
+	for i := 0; i < pingCount; i++ {
+		rtt, err := defaultPingFunc(ctx, c.doer, url)
+		if err != nil {
+			return measurement.Latency{}, measurement.NewError(measurement.ErrTransfer, measurement.PhaseLatency, url, err)
+		}
+
+		samples = append(samples, rtt)
+		c.conf.ReportLatency(url, rtt, time.Since(start))
+	}
+
+	return measurement.NewLatency(samples), nil
+}
+
+// ping requests empty response from provided url
+// and returns round trip time
+func ping(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		url,
+		nil,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := doer.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	end := time.Now()
+
+	return end.Sub(start), nil
+}
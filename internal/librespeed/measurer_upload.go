@@ -0,0 +1,230 @@
+package librespeed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/pkg/random"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// uploadSize is a size of content posted by single request
+	uploadSize = 1_000_000
+
+	// maxUploadStreams limits amount of concurrent
+	// requests in duration based upload test
+	maxUploadStreams = 16
+)
+
+// uploadSizes are sizes of content posted in duration
+// based upload test, from smallest to largest
+var uploadSizes = []int{100_000, 250_000, 500_000, 1_000_000, 2_500_000, 5_000_000, 10_000_000}
+
+// defaultUploadFunc is a variable to wrap upload function
+// for deterministic results
+var defaultUploadFunc = upload
+
+// MeasureUpload measures upload speed per second using LibreSpeed servers
+func (c *Client) MeasureUpload(ctx context.Context) (
+	uploadRate measurement.BitRate,
+	err error,
+) {
+	transfer, err := c.MeasureUploadTransfer(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return transfer.Rate, nil
+}
+
+// MeasureUploadTransfer measures upload speed per second using LibreSpeed servers
+// and returns details of measurement including per server results, failed servers
+// are handled according to failure policy, in case of error results measured
+// so far are returned as well
+func (c *Client) MeasureUploadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.selectServers(ctx)
+	if err != nil {
+		return measurement.Transfer{}, err
+	}
+
+	// payload is generated once before any meter is started, so its
+	// generation isn't measured, duration based test posts prefixes
+	// of it, so it's generated with the largest of sizes
+	size := uploadSize
+	if c.conf.TestDuration > 0 {
+		size = uploadSizes[len(uploadSizes)-1]
+	}
+	content := []byte(random.String(size))
+
+	// for each server calculate upload speeds
+	// and aggregate rates of succeeded ones
+	start := time.Now()
+	rates := make([]measurement.BitRate, 0, len(servers))
+	failures := 0
+	for _, server := range servers {
+		url := server.endpoint(server.UploadURL)
+
+		serverStart := time.Now()
+		meter := c.conf.NewMeter()
+		err := c.measureUpload(ctx, url, content, meter)
+		serverTransfer := measurement.ServerTransfer{
+			Server:   server.server(),
+			Duration: time.Since(serverStart),
+			Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseUpload, url, err),
+		}
+		if err != nil {
+			transfer.Servers = append(transfer.Servers, serverTransfer)
+			failures++
+			c.resetServers()
+			if !c.conf.Tolerates(failures, len(servers)) {
+				transfer.Duration = time.Since(start)
+				return transfer, transfer.Err()
+			}
+			continue
+		}
+
+		serverTransfer.Rate = meter.SampledRate()
+		serverTransfer.Bytes = meter.Bytes()
+		serverTransfer.Spread = meter.SampleSpread()
+		transfer.Servers = append(transfer.Servers, serverTransfer)
+
+		rates = append(rates, serverTransfer.Rate)
+		transfer.Bytes += serverTransfer.Bytes
+	}
+
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers(rates)
+	transfer.Duration = time.Since(start)
+
+	return transfer, nil
+}
+
+// measureUpload measures upload speed by posting content to provided
+// url in concurrent streams and counting uploaded bytes in meter, if
+// test duration is set, then upload test is time-boxed, otherwise
+// each stream sends single request
+func (c *Client) measureUpload(
+	ctx context.Context,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) error {
+	if c.conf.TestDuration > 0 {
+		return c.measureUploadAdaptive(ctx, url, content, meter)
+	}
+
+	eg := errgroup.Group{}
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseUpload, url)
+
+	for i := 0; i < workload; i++ {
+		eg.Go(func() error {
+			_, err := defaultUploadFunc(ctx, c.doer, url, content, meter)
+			return err
+		})
+	}
+	err := eg.Wait()
+	stopProgress()
+
+	return err
+}
+
+// measureUploadAdaptive measures upload speed by posting prefixes of
+// content to provided url in concurrent streams until test duration is
+// over, requests that finish too fast to saturate the link increase size
+// of posted content, and when the largest size is reached, amount of streams
+func (c *Client) measureUploadAdaptive(
+	ctx context.Context,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) error {
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseUpload, url)
+	defer stopProgress()
+
+	return measurement.Ramp(
+		ctx,
+		c.conf.TestDuration,
+		len(uploadSizes),
+		workload,
+		maxUploadStreams,
+		func(ctx context.Context, sizeIdx int) error {
+			_, err := defaultUploadFunc(ctx, c.doer, url, content[:uploadSizes[sizeIdx]], meter)
+			return err
+		},
+	)
+}
+
+// upload posts provided content to provided url, counting sent bytes
+// in meter, and returns amount of sent bytes, failed or truncated
+// request body is returned as error, content is only read, so it
+// can be shared by concurrent uploads
+func upload(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) (int64, error) {
+	body := &countingReader{r: meter.Reader(bytes.NewReader(content))}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		url,
+		body,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = int64(len(content))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return body.n, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return body.n, measurement.StatusError(resp.StatusCode)
+	}
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	meter.Stop()
+	if err != nil {
+		return body.n, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	if body.n < req.ContentLength {
+		return body.n, fmt.Errorf("failed to send request body: %w", io.ErrUnexpectedEOF)
+	}
+
+	return body.n, nil
+}
+
+// countingReader counts amount of bytes read from underlying reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read reads from underlying reader and counts read bytes
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,189 @@
+package librespeed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/librespeed/mocks"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureUpload(t *testing.T) {
+	t.Cleanup(func() {
+		defaultUploadFunc = upload
+		defaultPingFunc = ping
+	})
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
+	tableTests := map[string]struct {
+		setup             func() *mocks.HTTPDoer
+		expectedErr       error
+		expectedRateRange []measurement.BitRate
+	}{
+		"success-64-mbit": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://librespeed.org/backend-servers/servers.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[{"server":"//example.com/","ulURL":"empty.php"}]`)),
+				}, nil)
+
+				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
+					time.Sleep(time.Second / 2)
+					meter.Add(uploadSize)
+					return uploadSize, nil
+				}
+
+				return mockDoer
+			},
+			expectedErr:       nil,
+			expectedRateRange: []measurement.BitRate{55000000, 65000000},
+		},
+		"error-from-upload-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://librespeed.org/backend-servers/servers.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`[{"server":"//example.com/","ulURL":"empty.php"}]`)),
+				}, nil)
+
+				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
+					return 0, errors.New("random error")
+				}
+
+				return mockDoer
+			},
+			expectedErr:       errors.New("upload: transfer failed on https://example.com/empty.php: random error"),
+			expectedRateRange: []measurement.BitRate{0, 0},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(
+				&config.Config{
+					ServerCount: 1,
+				},
+				doer,
+			)
+
+			rate, err := cli.MeasureUpload(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+
+				assert.True(t, rate > testCase.expectedRateRange[0] &&
+					rate < testCase.expectedRateRange[1], rate)
+			}
+		})
+	}
+}
+
+func TestMeasureUploadAdaptive(t *testing.T) {
+	t.Cleanup(func() {
+		defaultUploadFunc = upload
+	})
+
+	mu := sync.Mutex{}
+	var sizes []int
+	defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
+		mu.Lock()
+		sizes = append(sizes, len(content))
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		meter.Add(int64(len(content)))
+		return int64(len(content)), nil
+	}
+
+	cli := NewClient(&config.Config{TestDuration: time.Second / 10}, mocks.NewHTTPDoer(t))
+
+	// content size grows, since requests finish
+	// faster than tenth of test duration
+	meter := measurement.NewMeter(0, 0, 0, nil)
+	content := make([]byte, uploadSizes[len(uploadSizes)-1])
+	err := cli.measureUploadAdaptive(context.Background(), "https://example.com/empty.php", content, meter)
+	assert.NoError(t, err)
+	assert.Equal(t, uploadSizes[0], sizes[0])
+	assert.Equal(t, uploadSizes[len(uploadSizes)-1], sizes[len(sizes)-1])
+}
+
+func TestUpload(t *testing.T) {
+	tableTests := map[string]struct {
+		setup         func() *mocks.HTTPDoer
+		expectedErr   error
+		expectedBytes int64
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					_, err := io.Copy(io.Discard, req.Body)
+					return err == nil && req.Method == http.MethodPost &&
+						req.URL.String() == "https://example.com/empty.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString("")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   nil,
+			expectedBytes: uploadSize,
+		},
+		"error-from-unsent-body": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString("")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to send request body: unexpected EOF"),
+			expectedBytes: 0,
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.Anything).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to send request: random error"),
+			expectedBytes: 0,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			meter := measurement.NewMeter(0, 0, 0, nil)
+			n, err := upload(context.Background(), doer, "https://example.com/empty.php", make([]byte, uploadSize), meter)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.expectedBytes, n)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package measurement
+
+import (
+	"math"
+	"sort"
+)
+
+// Aggregation combines multiple rates, e.g. rates of servers
+// or rates sampled during single transfer, into a single one
+type Aggregation func(rates []BitRate) BitRate
+
+// Mean returns arithmetic mean of rates
+func Mean(rates []BitRate) BitRate {
+	if len(rates) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, rate := range rates {
+		sum += float64(rate)
+	}
+
+	return BitRate(sum / float64(len(rates)))
+}
+
+// Median returns median of rates, it isn't
+// affected by a single outlier unlike mean
+func Median(rates []BitRate) BitRate {
+	return percentile(rates, 50)
+}
+
+// Maximum returns the highest of rates, it's how fast.com reports
+// speed, since slower rates are assumed to be caused by congestion
+func Maximum(rates []BitRate) BitRate {
+	if len(rates) == 0 {
+		return 0
+	}
+
+	maxRate := rates[0]
+	for _, rate := range rates[1:] {
+		if rate > maxRate {
+			maxRate = rate
+		}
+	}
+
+	return maxRate
+}
+
+// TrimmedMean returns aggregation calculating arithmetic mean of rates
+// after dropping provided fraction of the lowest and of the highest
+// rates, e.g. 0.1 drops 10% from each end. Fraction is clamped to
+// [0, 0.5), median is returned if no rates are left after trimming
+func TrimmedMean(fraction float64) Aggregation {
+	fraction = math.Max(0, math.Min(fraction, 0.5))
+
+	return func(rates []BitRate) BitRate {
+		trim := int(float64(len(rates)) * fraction)
+		if len(rates)-2*trim <= 0 {
+			return Median(rates)
+		}
+
+		return Mean(sorted(rates)[trim : len(rates)-trim])
+	}
+}
+
+// Percentile returns aggregation calculating p-th percentile of rates,
+// e.g. 90 for p90, p is clamped to [0, 100], values between
+// closest ranks are linearly interpolated
+func Percentile(p float64) Aggregation {
+	p = math.Max(0, math.Min(p, 100))
+
+	return func(rates []BitRate) BitRate {
+		return percentile(rates, p)
+	}
+}
+
+// percentile calculates p-th percentile of rates
+func percentile(rates []BitRate, p float64) BitRate {
+	if len(rates) == 0 {
+		return 0
+	}
+
+	s := sorted(rates)
+
+	rank := p / 100 * float64(len(s)-1)
+	low := int(math.Floor(rank))
+	high := int(math.Ceil(rank))
+
+	return s[low] + (s[high]-s[low])*BitRate(rank-float64(low))
+}
+
+// sorted returns sorted copy of rates
+func sorted(rates []BitRate) []BitRate {
+	s := make([]BitRate, len(rates))
+	copy(s, rates)
+	sort.Slice(s, func(i, j int) bool {
+		return s[i] < s[j]
+	})
+
+	return s
+}
+
+// Spread represents dispersion of aggregated rates,
+// it helps to judge confidence of measurement
+type Spread struct {
+	Min    BitRate
+	Max    BitRate
+	StdDev BitRate
+}
+
+// NewSpread calculates spread of rates, standard
+// deviation is calculated over the whole population
+func NewSpread(rates []BitRate) Spread {
+	if len(rates) == 0 {
+		return Spread{}
+	}
+
+	spread := Spread{
+		Min: rates[0],
+		Max: rates[0],
+	}
+
+	mean := float64(Mean(rates))
+	variance := 0.0
+	for _, rate := range rates {
+		if rate < spread.Min {
+			spread.Min = rate
+		}
+		if rate > spread.Max {
+			spread.Max = rate
+		}
+
+		variance += (float64(rate) - mean) * (float64(rate) - mean)
+	}
+	spread.StdDev = BitRate(math.Sqrt(variance / float64(len(rates))))
+
+	return spread
+}
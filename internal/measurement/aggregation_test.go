@@ -0,0 +1,109 @@
+package measurement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregation(t *testing.T) {
+	rates := []BitRate{40, 10, 30, 20, 1000}
+
+	tableTests := map[string]struct {
+		aggregation  Aggregation
+		rates        []BitRate
+		expectedRate BitRate
+	}{
+		"success-mean": {
+			aggregation:  Mean,
+			rates:        rates,
+			expectedRate: 220,
+		},
+		"success-median-odd": {
+			aggregation:  Median,
+			rates:        rates,
+			expectedRate: 30,
+		},
+		"success-median-even": {
+			aggregation:  Median,
+			rates:        []BitRate{40, 10, 30, 20},
+			expectedRate: 25,
+		},
+		"success-maximum": {
+			aggregation:  Maximum,
+			rates:        rates,
+			expectedRate: 1000,
+		},
+		"success-trimmed-mean": {
+			aggregation:  TrimmedMean(0.2),
+			rates:        rates,
+			expectedRate: 30,
+		},
+		"success-trimmed-mean-nothing-left": {
+			aggregation:  TrimmedMean(0.5),
+			rates:        []BitRate{40, 10, 30, 20},
+			expectedRate: 25,
+		},
+		"success-percentile": {
+			aggregation:  Percentile(90),
+			rates:        []BitRate{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110},
+			expectedRate: 100,
+		},
+		"success-percentile-interpolated": {
+			aggregation:  Percentile(90),
+			rates:        rates,
+			expectedRate: 616,
+		},
+		"success-empty": {
+			aggregation:  Median,
+			rates:        nil,
+			expectedRate: 0,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			rate := testCase.aggregation(testCase.rates)
+			assert.InDelta(t, float64(testCase.expectedRate), float64(rate), 1e-9)
+		})
+	}
+
+	// rates aren't reordered by aggregation
+	assert.Equal(t, []BitRate{40, 10, 30, 20, 1000}, rates)
+}
+
+func TestNewSpread(t *testing.T) {
+	tableTests := map[string]struct {
+		rates          []BitRate
+		expectedSpread Spread
+	}{
+		"success-multiple-rates": {
+			rates: []BitRate{2, 4, 4, 4, 5, 5, 7, 9},
+			expectedSpread: Spread{
+				Min:    2,
+				Max:    9,
+				StdDev: 2,
+			},
+		},
+		"success-single-rate": {
+			rates: []BitRate{10},
+			expectedSpread: Spread{
+				Min: 10,
+				Max: 10,
+			},
+		},
+		"success-no-rates": {
+			rates:          nil,
+			expectedSpread: Spread{},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			spread := NewSpread(testCase.rates)
+			assert.Equal(t, testCase.expectedSpread, spread)
+		})
+	}
+}
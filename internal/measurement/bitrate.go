@@ -1,16 +1,185 @@
 package measurement
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
 
 const (
 	kb = 1000
-	mb = 1000 * 1000
+	mb = 1000 * kb
+	gb = 1000 * mb
+	tb = 1000 * gb
+
+	kib = 1024
+	mib = 1024 * kib
+	gib = 1024 * mib
+	tib = 1024 * gib
+)
+
+// bitRateUnits maps unit names to amount of bits per second in them,
+// only case of prefix letter may vary in parsed units, e.g. "mbps",
+// while case of "b" and "B" is significant, because "Mb/s" and "MB/s" differ
+var bitRateUnits = map[string]float64{
+	"bps":   1,
+	"Kbps":  kb,
+	"Mbps":  mb,
+	"Gbps":  gb,
+	"Tbps":  tb,
+	"Kibps": kib,
+	"Mibps": mib,
+	"Gibps": gib,
+	"Tibps": tib,
+	"b/s":   1,
+	"Kb/s":  kb,
+	"Mb/s":  mb,
+	"Gb/s":  gb,
+	"Tb/s":  tb,
+	"B/s":   bitsInByte,
+	"KB/s":  bitsInByte * kb,
+	"MB/s":  bitsInByte * mb,
+	"GB/s":  bitsInByte * gb,
+	"TB/s":  bitsInByte * tb,
+	"KiB/s": bitsInByte * kib,
+	"MiB/s": bitsInByte * mib,
+	"GiB/s": bitsInByte * gib,
+	"TiB/s": bitsInByte * tib,
+}
+
+// Unit is a unit in which bit rate is formatted
+type Unit int
+
+const (
+	// Bits formats bit rate in bits per second, e.g. "94.200 Mbps"
+	Bits Unit = iota
+
+	// Bytes formats bit rate in bytes per second, e.g. "11.775 MB/s"
+	Bytes
 )
 
+// Prefix is a system of prefixes in which bit rate is formatted
+type Prefix int
+
+const (
+	// DecimalPrefix scales bit rate by powers of 1000, e.g. "MB/s"
+	DecimalPrefix Prefix = iota
+
+	// BinaryPrefix scales bit rate by powers of 1024, e.g. "MiB/s"
+	BinaryPrefix
+)
+
+// scaledUnits are unit names ordered by scale
+var scaledUnits = map[Unit]map[Prefix][]string{
+	Bits: {
+		DecimalPrefix: {"bps", "Kbps", "Mbps", "Gbps", "Tbps"},
+		BinaryPrefix:  {"bps", "Kibps", "Mibps", "Gibps", "Tibps"},
+	},
+	Bytes: {
+		DecimalPrefix: {"B/s", "KB/s", "MB/s", "GB/s", "TB/s"},
+		BinaryPrefix:  {"B/s", "KiB/s", "MiB/s", "GiB/s", "TiB/s"},
+	},
+}
+
 // BitRate represents measurement for download/upload speed
-// it can be one of: bps(bits), Kbps (kilobits), Mbps (megabits)
+// in bits per second
 type BitRate float64
 
+// Kbps returns bit rate of v Kilobits per second
+func Kbps(v float64) BitRate {
+	return BitRate(v * kb)
+}
+
+// Mbps returns bit rate of v Megabits per second
+func Mbps(v float64) BitRate {
+	return BitRate(v * mb)
+}
+
+// Gbps returns bit rate of v Gigabits per second
+func Gbps(v float64) BitRate {
+	return BitRate(v * gb)
+}
+
+// ParseBitRate parses bit rate from number optionally followed
+// by unit, e.g. "94.2 Mbps", "1.2Gbps" or "11.5 MiB/s", number
+// without unit is treated as bits per second
+func ParseBitRate(s string) (BitRate, error) {
+	s = strings.TrimSpace(s)
+
+	// unit starts after last digit or dot of number
+	i := strings.LastIndexAny(s, "0123456789.")
+	if i < 0 {
+		return 0, fmt.Errorf("failed to parse bit rate %q: missing number", s)
+	}
+
+	number, unit := s[:i+1], strings.TrimSpace(s[i+1:])
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse bit rate %q: %w", s, err)
+	}
+
+	if unit == "" {
+		return BitRate(value), nil
+	}
+
+	multiplier, ok := lookupUnit(unit)
+	if !ok {
+		return 0, fmt.Errorf("failed to parse bit rate %q: unknown unit %q", s, unit)
+	}
+
+	return BitRate(value * multiplier), nil
+}
+
+// lookupUnit returns amount of bits per second in unit
+func lookupUnit(unit string) (float64, bool) {
+	if multiplier, ok := bitRateUnits[unit]; ok {
+		return multiplier, true
+	}
+
+	// only prefix letter may be lower case, e.g. "mbps",
+	// "b" and "B" are never folded into each other
+	if len(unit) < 2 || !strings.ContainsRune("kmgt", rune(unit[0])) {
+		return 0, false
+	}
+
+	multiplier, ok := bitRateUnits[strings.ToUpper(unit[:1])+unit[1:]]
+
+	return multiplier, ok
+}
+
+// Kbps returns bit rate in Kilobits per second
+func (b BitRate) Kbps() float64 {
+	return float64(b) / kb
+}
+
+// Mbps returns bit rate in Megabits per second
+func (b BitRate) Mbps() float64 {
+	return float64(b) / mb
+}
+
+// Gbps returns bit rate in Gigabits per second
+func (b BitRate) Gbps() float64 {
+	return float64(b) / gb
+}
+
+// BytesPerSecond returns bit rate in bytes per second
+func (b BitRate) BytesPerSecond() float64 {
+	return float64(b) / bitsInByte
+}
+
+// MBps returns bit rate in Megabytes per second
+func (b BitRate) MBps() float64 {
+	return b.BytesPerSecond() / mb
+}
+
+// MiBps returns bit rate in Mebibytes per second
+func (b BitRate) MiBps() float64 {
+	return b.BytesPerSecond() / mib
+}
+
 // String returns prettified string representation
 // of bit rate measurement in bps by default
 func (b BitRate) String() string {
@@ -28,3 +197,103 @@ func (b BitRate) MbpsStr() string {
 func (b BitRate) KbpsStr() string {
 	return fmt.Sprintf("%.3f Kbps", b/kb)
 }
+
+// GbpsStr returns prettified string representation
+// of bit rate measurement in Gigabits
+func (b BitRate) GbpsStr() string {
+	return fmt.Sprintf("%.3f Gbps", b/gb)
+}
+
+// MBpsStr returns prettified string representation
+// of bit rate measurement in Megabytes per second
+func (b BitRate) MBpsStr() string {
+	return fmt.Sprintf("%.3f MB/s", b.MBps())
+}
+
+// MiBpsStr returns prettified string representation
+// of bit rate measurement in Mebibytes per second
+func (b BitRate) MiBpsStr() string {
+	return fmt.Sprintf("%.3f MiB/s", b.MiBps())
+}
+
+// HumanStr returns prettified string representation of bit rate
+// measurement scaled to the largest decimal unit in which it's
+// at least one, e.g. "2.400 Gbps" instead of "2400.000 Mbps"
+func (b BitRate) HumanStr() string {
+	return b.FormatUnit(Bits, DecimalPrefix)
+}
+
+// FormatUnit returns prettified string representation of bit rate
+// measurement in provided unit, scaled by provided prefix system
+// to the largest prefix in which it's at least one
+func (b BitRate) FormatUnit(unit Unit, prefix Prefix) string {
+	value := float64(b)
+	if unit == Bytes {
+		value /= bitsInByte
+	}
+
+	base := 1000.0
+	if prefix == BinaryPrefix {
+		base = 1024
+	}
+
+	names := scaledUnits[unit][prefix]
+	if names == nil {
+		names = scaledUnits[Bits][DecimalPrefix]
+	}
+
+	i := 0
+	for math.Abs(value) >= base && i < len(names)-1 {
+		value /= base
+		i++
+	}
+
+	return fmt.Sprintf("%.3f %s", value, names[i])
+}
+
+// MarshalText implements encoding.TextMarshaler, bit rate
+// is represented in Megabits without loss of precision,
+// e.g. "94.2 Mbps"
+func (b BitRate) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(b)/mb, 'f', -1, 64) + " Mbps"), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler,
+// it accepts strings in format accepted by ParseBitRate
+func (b *BitRate) UnmarshalText(text []byte) error {
+	rate, err := ParseBitRate(string(text))
+	if err != nil {
+		return err
+	}
+	*b = rate
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, bit rate is
+// represented as a number of bits per second, so it can be
+// processed without parsing
+func (b BitRate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it accepts either number
+// of bits per second or string in format accepted by UnmarshalText
+func (b *BitRate) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		return b.UnmarshalText([]byte(s))
+	}
+
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse bit rate: %w", err)
+	}
+	*b = BitRate(f)
+
+	return nil
+}
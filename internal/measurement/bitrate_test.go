@@ -1,6 +1,7 @@
 package measurement
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,3 +27,294 @@ func TestKbpsStr(t *testing.T) {
 	rateKbpsStr := rate.KbpsStr()
 	assert.Equal(t, "1051.254 Kbps", rateKbpsStr)
 }
+
+func TestMarshalText(t *testing.T) {
+	rate := BitRate(94_200_000)
+
+	text, err := rate.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "94.2 Mbps", string(text))
+}
+
+func TestUnmarshalText(t *testing.T) {
+	tableTests := map[string]struct {
+		text         string
+		expectedRate BitRate
+		expectedErr  string
+	}{
+		"success-mbps": {
+			text:         "94.2 Mbps",
+			expectedRate: 94_200_000,
+		},
+		"success-kbps-without-space": {
+			text:         "1200kbps",
+			expectedRate: 1_200_000,
+		},
+		"success-without-unit": {
+			text:         " 1500 ",
+			expectedRate: 1500,
+		},
+		"error-unknown-unit": {
+			text:        "94.2 Mbit",
+			expectedErr: `failed to parse bit rate "94.2 Mbit": unknown unit "Mbit"`,
+		},
+		"error-missing-number": {
+			text:        "Mbps",
+			expectedErr: `failed to parse bit rate "Mbps": missing number`,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			var rate BitRate
+			err := rate.UnmarshalText([]byte(testCase.text))
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.InDelta(t, float64(testCase.expectedRate), float64(rate), 1e-6)
+			}
+		})
+	}
+}
+
+func TestBitRateJSON(t *testing.T) {
+	data, err := json.Marshal(BitRate(94_200_000))
+	assert.NoError(t, err)
+	assert.Equal(t, "94200000", string(data))
+
+	tableTests := map[string]struct {
+		data         string
+		expectedRate BitRate
+		expectedErr  bool
+	}{
+		"success-number": {
+			data:         "94200000",
+			expectedRate: 94_200_000,
+		},
+		"success-string": {
+			data:         `"94.2 Mbps"`,
+			expectedRate: 94_200_000,
+		},
+		"error-invalid": {
+			data:        "true",
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			var rate BitRate
+			err := json.Unmarshal([]byte(testCase.data), &rate)
+			if testCase.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.InDelta(t, float64(testCase.expectedRate), float64(rate), 1e-6)
+			}
+		})
+	}
+}
+
+func TestBitRateConstructors(t *testing.T) {
+	assert.InDelta(t, 1_200, float64(Kbps(1.2)), 1e-9)
+	assert.InDelta(t, 94_200_000, float64(Mbps(94.2)), 1e-6)
+	assert.InDelta(t, 2_400_000_000, float64(Gbps(2.4)), 1e-3)
+
+	rate := Gbps(2.4)
+	assert.InDelta(t, 2_400_000, rate.Kbps(), 1e-6)
+	assert.InDelta(t, 2_400, rate.Mbps(), 1e-9)
+	assert.InDelta(t, 2.4, rate.Gbps(), 1e-12)
+	assert.InDelta(t, 300_000_000, rate.BytesPerSecond(), 1e-3)
+	assert.InDelta(t, 300, rate.MBps(), 1e-9)
+	assert.InDelta(t, 286.102, rate.MiBps(), 1e-3)
+}
+
+func TestParseBitRate(t *testing.T) {
+	tableTests := map[string]struct {
+		s            string
+		expectedRate BitRate
+		expectedErr  string
+	}{
+		"success-gbps": {
+			s:            "1.2 Gbps",
+			expectedRate: 1_200_000_000,
+		},
+		"success-binary-bits": {
+			s:            "1 Mibps",
+			expectedRate: 1024 * 1024,
+		},
+		"success-decimal-bytes": {
+			s:            "11.5MB/s",
+			expectedRate: 92_000_000,
+		},
+		"success-binary-bytes": {
+			s:            "1 KiB/s",
+			expectedRate: 8192,
+		},
+		"success-bits-per-second-slash": {
+			s:            "10 Mb/s",
+			expectedRate: 10_000_000,
+		},
+		"success-lower-case-prefix": {
+			s:            "10 gbps",
+			expectedRate: 10_000_000_000,
+		},
+		"success-lower-case-prefix-bytes": {
+			s:            "2 mB/s",
+			expectedRate: 16_000_000,
+		},
+		"success-tera-bits": {
+			s:            "2 Tbps",
+			expectedRate: 2_000_000_000_000,
+		},
+		"success-tebi-bytes": {
+			s:            "1 TiB/s",
+			expectedRate: 8 * 1024 * 1024 * 1024 * 1024,
+		},
+		"error-bytes-in-bps": {
+			s:           "11.5 MBps",
+			expectedErr: `failed to parse bit rate "11.5 MBps": unknown unit "MBps"`,
+		},
+		"error-upper-case-bits": {
+			s:           "10 GBPS",
+			expectedErr: `failed to parse bit rate "10 GBPS": unknown unit "GBPS"`,
+		},
+		"error-lower-case-bytes": {
+			s:           "10 b/S",
+			expectedErr: `failed to parse bit rate "10 b/S": unknown unit "b/S"`,
+		},
+		"error-invalid-number": {
+			s:           "1.2.3 Mbps",
+			expectedErr: `failed to parse bit rate "1.2.3 Mbps": strconv.ParseFloat: parsing "1.2.3": invalid syntax`,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			rate, err := ParseBitRate(testCase.s)
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.InDelta(t, float64(testCase.expectedRate), float64(rate), 1e-3)
+			}
+		})
+	}
+}
+
+func TestFormatUnit(t *testing.T) {
+	tableTests := map[string]struct {
+		rate        BitRate
+		unit        Unit
+		prefix      Prefix
+		expectedStr string
+	}{
+		"success-bits-decimal-gbps": {
+			rate:        Mbps(2400),
+			unit:        Bits,
+			prefix:      DecimalPrefix,
+			expectedStr: "2.400 Gbps",
+		},
+		"success-bits-decimal-bps": {
+			rate:        999,
+			unit:        Bits,
+			prefix:      DecimalPrefix,
+			expectedStr: "999.000 bps",
+		},
+		"success-bits-binary": {
+			rate:        1024 * 1024 * 3,
+			unit:        Bits,
+			prefix:      BinaryPrefix,
+			expectedStr: "3.000 Mibps",
+		},
+		"success-bytes-decimal": {
+			rate:        Mbps(94.2),
+			unit:        Bytes,
+			prefix:      DecimalPrefix,
+			expectedStr: "11.775 MB/s",
+		},
+		"success-bytes-binary": {
+			rate:        Gbps(2.4),
+			unit:        Bytes,
+			prefix:      BinaryPrefix,
+			expectedStr: "286.102 MiB/s",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedStr, testCase.rate.FormatUnit(testCase.unit, testCase.prefix))
+		})
+	}
+}
+
+func TestFormatUnitParseRoundTrip(t *testing.T) {
+	tableTests := map[string]struct {
+		unit   Unit
+		prefix Prefix
+	}{
+		"bits-decimal":  {unit: Bits, prefix: DecimalPrefix},
+		"bits-binary":   {unit: Bits, prefix: BinaryPrefix},
+		"bytes-decimal": {unit: Bytes, prefix: DecimalPrefix},
+		"bytes-binary":  {unit: Bytes, prefix: BinaryPrefix},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			base := 1000.0
+			if testCase.prefix == BinaryPrefix {
+				base = 1024
+			}
+
+			scale := 1.0
+			if testCase.unit == Bytes {
+				scale = bitsInByte
+			}
+
+			for _, name := range scaledUnits[testCase.unit][testCase.prefix] {
+				rate := BitRate(2 * scale)
+
+				str := rate.FormatUnit(testCase.unit, testCase.prefix)
+				assert.Equal(t, "2.000 "+name, str)
+
+				parsed, err := ParseBitRate(str)
+				if assert.NoError(t, err, name) {
+					assert.InDelta(t, float64(rate), float64(parsed), float64(rate)*1e-9, name)
+				}
+
+				scale *= base
+			}
+		})
+	}
+
+	parsed, err := ParseBitRate(Gbps(2000).HumanStr())
+	assert.NoError(t, err)
+	assert.Equal(t, Gbps(2000), parsed)
+
+	var rate BitRate
+	assert.NoError(t, rate.UnmarshalText([]byte(Gbps(2000).HumanStr())))
+	assert.Equal(t, Gbps(2000), rate)
+}
+
+func TestHumanStr(t *testing.T) {
+	assert.Equal(t, "2.400 Gbps", Mbps(2400).HumanStr())
+	assert.Equal(t, "94.200 Mbps", Mbps(94.2).HumanStr())
+	assert.Equal(t, "1.200 Kbps", Kbps(1.2).HumanStr())
+}
+
+func TestGbpsStr(t *testing.T) {
+	assert.Equal(t, "2.400 Gbps", Mbps(2400).GbpsStr())
+}
+
+func TestBytesStr(t *testing.T) {
+	rate := Mbps(94.2)
+
+	assert.Equal(t, "11.775 MB/s", rate.MBpsStr())
+	assert.Equal(t, "11.230 MiB/s", rate.MiBpsStr())
+}
@@ -0,0 +1,124 @@
+package measurement
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrServerDiscovery is matched by errors of requesting
+	// list of servers from measurement tool
+	ErrServerDiscovery = errors.New("server discovery failed")
+
+	// ErrNoServers is matched by errors of empty server
+	// list or of servers that are all unreachable
+	ErrNoServers = errors.New("no servers available")
+
+	// ErrTokenInvalid is matched by errors of authentication
+	// token rejected by measurement tool
+	ErrTokenInvalid = errors.New("token is invalid")
+
+	// ErrTransfer is matched by errors of download, upload
+	// or latency measurement against server
+	ErrTransfer = errors.New("transfer failed")
+)
+
+// MeasurementError describes failed measurement, it matches
+// its kind with errors.Is and unwraps to underlying error
+type MeasurementError struct {
+	// Kind is one of ErrServerDiscovery, ErrNoServers,
+	// ErrTokenInvalid and ErrTransfer
+	Kind error
+
+	// Phase is a phase of failed measurement, it's empty
+	// if failure isn't specific to phase, e.g. discovery
+	Phase Phase
+
+	// Server is URL of failed server or of server list
+	Server string
+
+	// StatusCode is HTTP status code of response,
+	// zero if no unexpected response was received
+	StatusCode int
+
+	// Err is underlying error
+	Err error
+}
+
+// Error returns description of failed measurement,
+// e.g. "download: transfer failed on https://example.com: ..."
+func (e *MeasurementError) Error() string {
+	// details aren't known yet, e.g. when error
+	// of status code isn't filled by NewError
+	if e.Kind == nil && e.Phase == "" && e.Server == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+
+	b := strings.Builder{}
+	if e.Phase != "" {
+		b.WriteString(string(e.Phase) + ": ")
+	}
+	if e.Kind != nil {
+		b.WriteString(e.Kind.Error())
+	} else {
+		b.WriteString("measurement failed")
+	}
+	if e.Server != "" {
+		b.WriteString(" on " + e.Server)
+	}
+	if e.Err != nil {
+		b.WriteString(": " + e.Err.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns underlying error
+func (e *MeasurementError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a kind of measurement error
+func (e *MeasurementError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
+// NewError returns measurement error of provided kind, phase and
+// server, if err is already a measurement error, then only its
+// missing details are filled
+func NewError(kind error, phase Phase, server string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if merr, ok := err.(*MeasurementError); ok {
+		filled := *merr
+		if filled.Kind == nil {
+			filled.Kind = kind
+		}
+		if filled.Phase == "" {
+			filled.Phase = phase
+		}
+		if filled.Server == "" {
+			filled.Server = server
+		}
+		return &filled
+	}
+
+	return &MeasurementError{
+		Kind:   kind,
+		Phase:  phase,
+		Server: server,
+		Err:    err,
+	}
+}
+
+// StatusError returns error of response with unexpected
+// status code, kind and server are filled by NewError
+func StatusError(statusCode int) error {
+	return &MeasurementError{
+		StatusCode: statusCode,
+		Err:        fmt.Errorf("unexpected status code %d", statusCode),
+	}
+}
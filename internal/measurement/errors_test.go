@@ -0,0 +1,85 @@
+package measurement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasurementError(t *testing.T) {
+	tableTests := map[string]struct {
+		err             error
+		expectedMessage string
+		expectedKind    error
+		expectedCause   error
+	}{
+		"success-transfer": {
+			err:             NewError(ErrTransfer, PhaseDownload, "https://example.com", context.DeadlineExceeded),
+			expectedMessage: "download: transfer failed on https://example.com: context deadline exceeded",
+			expectedKind:    ErrTransfer,
+			expectedCause:   context.DeadlineExceeded,
+		},
+		"success-discovery-without-phase": {
+			err:             NewError(ErrServerDiscovery, "", "https://example.com/servers", errors.New("random error")),
+			expectedMessage: "server discovery failed on https://example.com/servers: random error",
+			expectedKind:    ErrServerDiscovery,
+		},
+		"success-status-code-filled": {
+			err:             NewError(ErrTransfer, PhaseUpload, "https://example.com", StatusError(503)),
+			expectedMessage: "upload: transfer failed on https://example.com: unexpected status code 503",
+			expectedKind:    ErrTransfer,
+		},
+		"success-kind-kept": {
+			err: NewError(ErrServerDiscovery, "", "https://example.com", &MeasurementError{
+				Kind:       ErrTokenInvalid,
+				StatusCode: 403,
+				Err:        errors.New("random error"),
+			}),
+			expectedMessage: "token is invalid on https://example.com: random error",
+			expectedKind:    ErrTokenInvalid,
+		},
+		"success-wrapped": {
+			err:             fmt.Errorf("2 of 3 servers failed: %w", NewError(ErrTransfer, PhaseLatency, "https://example.com", errors.New("random error"))),
+			expectedMessage: "2 of 3 servers failed: latency: transfer failed on https://example.com: random error",
+			expectedKind:    ErrTransfer,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedMessage, testCase.err.Error())
+			assert.True(t, errors.Is(testCase.err, testCase.expectedKind))
+
+			for _, kind := range []error{ErrServerDiscovery, ErrNoServers, ErrTokenInvalid, ErrTransfer} {
+				if kind != testCase.expectedKind {
+					assert.False(t, errors.Is(testCase.err, kind), kind)
+				}
+			}
+			if testCase.expectedCause != nil {
+				assert.True(t, errors.Is(testCase.err, testCase.expectedCause))
+			}
+
+			var merr *MeasurementError
+			assert.True(t, errors.As(testCase.err, &merr))
+		})
+	}
+}
+
+func TestNewError(t *testing.T) {
+	assert.NoError(t, NewError(ErrTransfer, PhaseDownload, "https://example.com", nil))
+	assert.Equal(t, "unexpected status code 500", StatusError(500).Error())
+
+	err := NewError(ErrTransfer, PhaseDownload, "https://example.com", StatusError(500))
+
+	var merr *MeasurementError
+	if assert.True(t, errors.As(err, &merr)) {
+		assert.Equal(t, ErrTransfer, merr.Kind)
+		assert.Equal(t, PhaseDownload, merr.Phase)
+		assert.Equal(t, "https://example.com", merr.Server)
+		assert.Equal(t, 500, merr.StatusCode)
+	}
+}
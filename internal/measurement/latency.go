@@ -0,0 +1,100 @@
+package measurement
+
+import (
+	"fmt"
+	"time"
+)
+
+// Latency represents round trip time measurement
+// between client and server
+type Latency struct {
+	Min    time.Duration
+	Avg    time.Duration
+	Max    time.Duration
+	Jitter time.Duration
+}
+
+// NewLatency calculates latency statistics from provided
+// round trip time samples, jitter is calculated as a mean
+// difference between consecutive samples
+func NewLatency(samples []time.Duration) Latency {
+	if len(samples) == 0 {
+		return Latency{}
+	}
+
+	latency := Latency{
+		Min: samples[0],
+		Max: samples[0],
+	}
+
+	var sum, jitterSum time.Duration
+	for i, sample := range samples {
+		sum += sample
+
+		if sample < latency.Min {
+			latency.Min = sample
+		}
+		if sample > latency.Max {
+			latency.Max = sample
+		}
+
+		if i > 0 {
+			jitterSum += absDuration(sample - samples[i-1])
+		}
+	}
+
+	latency.Avg = sum / time.Duration(len(samples))
+	if len(samples) > 1 {
+		latency.Jitter = jitterSum / time.Duration(len(samples)-1)
+	}
+
+	return latency
+}
+
+// AverageLatency combines latencies measured against
+// different servers into single one
+func AverageLatency(latencies []Latency) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+
+	avgLatency := Latency{
+		Min: latencies[0].Min,
+		Max: latencies[0].Max,
+	}
+
+	var avgSum, jitterSum time.Duration
+	for _, latency := range latencies {
+		avgSum += latency.Avg
+		jitterSum += latency.Jitter
+
+		if latency.Min < avgLatency.Min {
+			avgLatency.Min = latency.Min
+		}
+		if latency.Max > avgLatency.Max {
+			avgLatency.Max = latency.Max
+		}
+	}
+
+	avgLatency.Avg = avgSum / time.Duration(len(latencies))
+	avgLatency.Jitter = jitterSum / time.Duration(len(latencies))
+
+	return avgLatency
+}
+
+// String returns prettified string representation
+// of latency measurement
+func (l Latency) String() string {
+	return fmt.Sprintf(
+		"min %s, avg %s, max %s, jitter %s",
+		l.Min, l.Avg, l.Max, l.Jitter,
+	)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
@@ -0,0 +1,74 @@
+package measurement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLatency(t *testing.T) {
+	tableTests := map[string]struct {
+		samples         []time.Duration
+		expectedLatency Latency
+	}{
+		"success-multiple-samples": {
+			samples: []time.Duration{
+				10 * time.Millisecond,
+				20 * time.Millisecond,
+				15 * time.Millisecond,
+				15 * time.Millisecond,
+			},
+			expectedLatency: Latency{
+				Min:    10 * time.Millisecond,
+				Avg:    15 * time.Millisecond,
+				Max:    20 * time.Millisecond,
+				Jitter: 5 * time.Millisecond,
+			},
+		},
+		"success-single-sample": {
+			samples: []time.Duration{10 * time.Millisecond},
+			expectedLatency: Latency{
+				Min: 10 * time.Millisecond,
+				Avg: 10 * time.Millisecond,
+				Max: 10 * time.Millisecond,
+			},
+		},
+		"success-no-samples": {
+			samples:         nil,
+			expectedLatency: Latency{},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			latency := NewLatency(testCase.samples)
+			assert.Equal(t, testCase.expectedLatency, latency)
+		})
+	}
+}
+
+func TestAverageLatency(t *testing.T) {
+	latency := AverageLatency([]Latency{
+		{
+			Min:    10 * time.Millisecond,
+			Avg:    15 * time.Millisecond,
+			Max:    20 * time.Millisecond,
+			Jitter: 2 * time.Millisecond,
+		},
+		{
+			Min:    20 * time.Millisecond,
+			Avg:    25 * time.Millisecond,
+			Max:    40 * time.Millisecond,
+			Jitter: 4 * time.Millisecond,
+		},
+	})
+
+	assert.Equal(t, Latency{
+		Min:    10 * time.Millisecond,
+		Avg:    20 * time.Millisecond,
+		Max:    40 * time.Millisecond,
+		Jitter: 3 * time.Millisecond,
+	}, latency)
+}
@@ -0,0 +1,258 @@
+package measurement
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const bitsInByte = 8
+
+// Meter counts transferred bytes and calculates transfer rate,
+// bytes transferred during warmup period are excluded from rate,
+// so only steady state of transfer is measured.
+//
+// It's safe for concurrent use
+type Meter struct {
+	mu sync.Mutex
+
+	warmupDuration time.Duration
+	warmupBytes    int64
+	sampleInterval time.Duration
+	aggregation    Aggregation
+
+	start time.Time
+	last  time.Time
+	bytes int64
+
+	// warm is set when warmup period is over,
+	// warmStart and warmBytes hold time and amount
+	// of bytes transferred at the end of warmup
+	warm      bool
+	warmStart time.Time
+	warmBytes int64
+
+	// samples are rates of steady state transfer measured
+	// every sample interval, sampleStart and sampleBytes hold
+	// time and amount of bytes transferred at the start of
+	// current sample
+	samples     []BitRate
+	sampleStart time.Time
+	sampleBytes int64
+}
+
+// NewMeter creates meter and starts measuring, warmup period is over
+// when either of warmup duration or warmup bytes is reached, zero
+// values mean that there is no warmup period. If sample interval
+// is set, then transfer rate is sampled every interval after warmup
+// and samples are combined by aggregation, nil aggregation means Mean
+func NewMeter(
+	warmupDuration time.Duration,
+	warmupBytes int64,
+	sampleInterval time.Duration,
+	aggregation Aggregation,
+) *Meter {
+	now := time.Now()
+
+	if aggregation == nil {
+		aggregation = Mean
+	}
+
+	m := &Meter{
+		warmupDuration: warmupDuration,
+		warmupBytes:    warmupBytes,
+		sampleInterval: sampleInterval,
+		aggregation:    aggregation,
+		start:          now,
+		last:           now,
+	}
+
+	if warmupDuration <= 0 && warmupBytes <= 0 {
+		m.warm = true
+		m.warmStart = now
+		m.sampleStart = now
+	}
+
+	return m
+}
+
+// Add adds amount of transferred bytes to meter
+func (m *Meter) Add(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.bytes += n
+	m.last = now
+
+	if m.warm {
+		m.sample(now)
+		return
+	}
+
+	if (m.warmupDuration > 0 && now.Sub(m.start) >= m.warmupDuration) ||
+		(m.warmupBytes > 0 && m.bytes >= m.warmupBytes) {
+		m.warm = true
+		m.warmStart = now
+		m.warmBytes = m.bytes
+		m.sampleStart = now
+		m.sampleBytes = m.bytes
+	}
+}
+
+// sample records rate of current sample if sample interval passed
+func (m *Meter) sample(now time.Time) {
+	if m.sampleInterval <= 0 {
+		return
+	}
+
+	elapsed := now.Sub(m.sampleStart)
+	if elapsed < m.sampleInterval {
+		return
+	}
+
+	m.samples = append(
+		m.samples,
+		BitRate(float64(m.bytes-m.sampleBytes)*bitsInByte/elapsed.Seconds()),
+	)
+	m.sampleStart = now
+	m.sampleBytes = m.bytes
+}
+
+// Stop marks end of transfer at current time, it's needed
+// when transfer isn't finished by last transferred bytes,
+// e.g. upload is finished only when server responded
+func (m *Meter) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.last = time.Now()
+}
+
+// Write implements io.Writer, so response bodies
+// can be copied directly to meter
+func (m *Meter) Write(p []byte) (int, error) {
+	m.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Reader returns reader that adds bytes read from r to meter
+func (m *Meter) Reader(r io.Reader) io.Reader {
+	return &meterReader{r: r, m: m}
+}
+
+// Bytes returns total amount of transferred bytes,
+// including ones transferred during warmup
+func (m *Meter) Bytes() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.bytes
+}
+
+// Rate returns transfer rate of steady state, if transfer
+// finished before warmup period was over, then rate of
+// the whole transfer is returned
+func (m *Meter) Rate() BitRate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start, bytes := m.start, m.bytes
+	if m.warm && m.last.After(m.warmStart) {
+		start, bytes = m.warmStart, m.bytes-m.warmBytes
+	}
+
+	elapsed := m.last.Sub(start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return BitRate(float64(bytes) * bitsInByte / elapsed)
+}
+
+// Samples returns rates of steady state transfer
+// sampled every sample interval
+func (m *Meter) Samples() []BitRate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	samples := make([]BitRate, len(m.samples))
+	copy(samples, m.samples)
+
+	return samples
+}
+
+// SampledRate returns sampled rates combined by aggregation of meter,
+// so it reflects sustained transfer, if there are no samples,
+// then steady state rate is returned
+func (m *Meter) SampledRate() BitRate {
+	samples := m.Samples()
+	if len(samples) == 0 {
+		return m.Rate()
+	}
+
+	return m.aggregation(samples)
+}
+
+// SampleSpread returns spread of sampled rates,
+// it's empty if there are no samples
+func (m *Meter) SampleSpread() Spread {
+	return NewSpread(m.Samples())
+}
+
+// Report calls fn every interval with progress of transfer, phase and
+// server are taken from provided progress, returned function stops
+// reporting and calls fn with final progress
+func (m *Meter) Report(interval time.Duration, progress Progress, fn func(Progress)) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		prevTime, prevBytes := m.start, int64(0)
+		report := func(now time.Time) {
+			bytes := m.Bytes()
+
+			p := progress
+			p.Bytes = bytes
+			p.Elapsed = now.Sub(m.start)
+			if elapsed := now.Sub(prevTime).Seconds(); elapsed > 0 {
+				p.Rate = BitRate(float64(bytes-prevBytes) * bitsInByte / elapsed)
+			}
+			fn(p)
+
+			prevTime, prevBytes = now, bytes
+		}
+
+		for {
+			select {
+			case now := <-ticker.C:
+				report(now)
+			case <-done:
+				report(time.Now())
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+type meterReader struct {
+	r io.Reader
+	m *Meter
+}
+
+// Read reads from underlying reader and adds read bytes to meter
+func (r *meterReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.m.Add(int64(n))
+	return n, err
+}
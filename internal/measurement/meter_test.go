@@ -0,0 +1,133 @@
+package measurement
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeterRate(t *testing.T) {
+	tableTests := map[string]struct {
+		warmupDuration    time.Duration
+		warmupBytes       int64
+		expectedBytes     int64
+		expectedRateRange []BitRate
+	}{
+		// 1000 bytes in 100ms
+		"success-no-warmup": {
+			expectedBytes:     1000,
+			expectedRateRange: []BitRate{60_000, 80_001},
+		},
+		// 800 bytes in 80ms, first 200 bytes are excluded
+		"success-warmup-bytes": {
+			warmupBytes:       200,
+			expectedBytes:     1000,
+			expectedRateRange: []BitRate{60_000, 80_001},
+		},
+		// 700 bytes in 70ms, first 30ms are excluded
+		"success-warmup-duration": {
+			warmupDuration:    25 * time.Millisecond,
+			expectedBytes:     1000,
+			expectedRateRange: []BitRate{60_000, 80_001},
+		},
+		// transfer is shorter than warmup, so whole transfer is measured
+		"success-warmup-longer-than-transfer": {
+			warmupDuration:    time.Second,
+			expectedBytes:     1000,
+			expectedRateRange: []BitRate{60_000, 80_001},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			meter := NewMeter(testCase.warmupDuration, testCase.warmupBytes, 0, nil)
+			for i := 0; i < 10; i++ {
+				time.Sleep(10 * time.Millisecond)
+				meter.Add(100)
+			}
+
+			rate := meter.Rate()
+			assert.Equal(t, testCase.expectedBytes, meter.Bytes())
+			assert.True(t, rate >= testCase.expectedRateRange[0] &&
+				rate < testCase.expectedRateRange[1], rate)
+		})
+	}
+}
+
+func TestMeterReader(t *testing.T) {
+	meter := NewMeter(0, 0, 0, nil)
+
+	n, err := io.Copy(io.Discard, meter.Reader(bytes.NewBufferString("blob")))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.Equal(t, int64(4), meter.Bytes())
+}
+
+func TestMeterSamples(t *testing.T) {
+	meter := NewMeter(0, 0, 20*time.Millisecond, nil)
+	for i := 0; i < 10; i++ {
+		time.Sleep(10 * time.Millisecond)
+		meter.Add(100)
+	}
+
+	samples := meter.Samples()
+	assert.True(t, len(samples) >= 4 && len(samples) <= 5, len(samples))
+	for _, sample := range samples {
+		assert.True(t, sample >= 50_000 && sample < 80_001, sample)
+	}
+
+	rate := meter.SampledRate()
+	assert.True(t, rate >= 50_000 && rate < 80_001, rate)
+}
+
+func TestMeterReport(t *testing.T) {
+	meter := NewMeter(0, 0, 0, nil)
+
+	var reports []Progress
+	stop := meter.Report(
+		20*time.Millisecond,
+		Progress{
+			Phase:  PhaseDownload,
+			Server: "https://example.com",
+		},
+		func(p Progress) {
+			reports = append(reports, p)
+		},
+	)
+	for i := 0; i < 10; i++ {
+		time.Sleep(10 * time.Millisecond)
+		meter.Add(100)
+	}
+	stop()
+
+	assert.True(t, len(reports) >= 4, len(reports))
+	for _, report := range reports {
+		assert.Equal(t, PhaseDownload, report.Phase)
+		assert.Equal(t, "https://example.com", report.Server)
+	}
+
+	// final report is sent on stop
+	last := reports[len(reports)-1]
+	assert.Equal(t, int64(1000), last.Bytes)
+	assert.True(t, last.Elapsed >= 100*time.Millisecond, last.Elapsed)
+}
+
+func TestMeterSampleAggregation(t *testing.T) {
+	meter := NewMeter(0, 0, 20*time.Millisecond, Maximum)
+	for i := 0; i < 10; i++ {
+		time.Sleep(10 * time.Millisecond)
+		meter.Add(int64(100 * (i%2 + 1)))
+	}
+
+	samples := meter.Samples()
+	assert.NotEmpty(t, samples)
+	assert.Equal(t, Maximum(samples), meter.SampledRate())
+
+	spread := meter.SampleSpread()
+	assert.Equal(t, NewSpread(samples), spread)
+	assert.True(t, spread.Min <= spread.Max)
+}
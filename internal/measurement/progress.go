@@ -0,0 +1,31 @@
+package measurement
+
+import "time"
+
+// Phase is a phase of measurement
+type Phase string
+
+const (
+	PhaseLatency  Phase = "latency"
+	PhaseDownload Phase = "download"
+	PhaseUpload   Phase = "upload"
+)
+
+// Progress represents intermediate state of measurement
+// against single server
+type Progress struct {
+	Phase  Phase
+	Server string
+
+	// Rate is an instantaneous transfer rate since previous report
+	Rate BitRate
+
+	// Bytes is a cumulative amount of transferred bytes
+	Bytes int64
+
+	// Latency is a round trip time of the last ping,
+	// it's set only for latency phase
+	Latency time.Duration
+
+	Elapsed time.Duration
+}
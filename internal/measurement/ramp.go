@@ -0,0 +1,112 @@
+package measurement
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// rampUpRounds defines how short a transfer of duration based test
+// should be, relative to test duration, for payload size or amount
+// of streams to be increased
+const rampUpRounds = 10
+
+// RampFunc transfers a single payload of size with provided index,
+// transferred bytes are expected to be counted by meter as they
+// arrive, so interrupted transfer is still accounted for
+type RampFunc func(ctx context.Context, sizeIdx int) error
+
+// Ramp runs transfers in concurrent streams until duration is over.
+// Every stream sends transfers one after another without waiting for
+// other streams, and transfer that finishes faster than tenth of
+// duration increases index of payload size, or when the largest of
+// sizes is reached, adds a stream, until there are maxStreams of them.
+// Transfers interrupted by the end of duration aren't errors
+func Ramp(
+	ctx context.Context,
+	duration time.Duration,
+	sizes int,
+	streams int,
+	maxStreams int,
+	transfer RampFunc,
+) error {
+	rampCtx, cancel := context.WithDeadline(ctx, time.Now().Add(duration))
+	defer cancel()
+
+	eg, egCtx := errgroup.WithContext(rampCtx)
+	r := &ramp{
+		sizes:      sizes,
+		streams:    streams,
+		maxStreams: maxStreams,
+	}
+
+	var stream func() error
+	stream = func() error {
+		for {
+			start := time.Now()
+			err := transfer(egCtx, r.sizeIdx())
+			switch {
+			case ctx.Err() != nil:
+				// measurement is canceled
+				if err == nil {
+					err = ctx.Err()
+				}
+				return err
+			case rampCtx.Err() != nil:
+				// duration is over
+				return nil
+			case err != nil:
+				return err
+			}
+
+			if time.Since(start) < duration/rampUpRounds && r.grow() {
+				eg.Go(stream)
+			}
+		}
+	}
+
+	for i := 0; i < streams; i++ {
+		eg.Go(stream)
+	}
+
+	return eg.Wait()
+}
+
+// ramp holds state of ramp up shared by streams
+type ramp struct {
+	mu sync.Mutex
+
+	idx        int
+	sizes      int
+	streams    int
+	maxStreams int
+}
+
+// sizeIdx returns index of current payload size
+func (r *ramp) sizeIdx() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.idx
+}
+
+// grow increases payload size, or when the largest size is
+// reached, amount of streams, it reports whether a stream
+// should be added
+func (r *ramp) grow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.idx < r.sizes-1 {
+		r.idx++
+		return false
+	}
+	if r.streams < r.maxStreams {
+		r.streams++
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package measurement
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRamp(t *testing.T) {
+	tableTests := map[string]struct {
+		ctx             func() (context.Context, context.CancelFunc)
+		transfer        func(ctx context.Context, sizeIdx int) error
+		expectedErr     error
+		expectedElapsed []time.Duration
+	}{
+		"success-interrupted-by-deadline": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			transfer: func(ctx context.Context, sizeIdx int) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return nil
+				}
+			},
+			expectedErr:     nil,
+			expectedElapsed: []time.Duration{300 * time.Millisecond, 400 * time.Millisecond},
+		},
+		"error-from-transfer-fail": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			transfer: func(ctx context.Context, sizeIdx int) error {
+				return errors.New("random error")
+			},
+			expectedErr:     errors.New("random error"),
+			expectedElapsed: []time.Duration{0, 100 * time.Millisecond},
+		},
+		"error-from-canceled-context": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 100*time.Millisecond)
+			},
+			transfer: func(ctx context.Context, sizeIdx int) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			expectedErr:     context.DeadlineExceeded,
+			expectedElapsed: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			ctx, cancel := testCase.ctx()
+			defer cancel()
+
+			start := time.Now()
+			err := Ramp(ctx, 300*time.Millisecond, 3, 2, 4, testCase.transfer)
+			elapsed := time.Since(start)
+			if testCase.expectedErr != nil {
+				assert.EqualError(t, err, testCase.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.True(t, elapsed >= testCase.expectedElapsed[0] &&
+				elapsed < testCase.expectedElapsed[1], elapsed)
+		})
+	}
+}
+
+func TestRampGrowth(t *testing.T) {
+	mu := sync.Mutex{}
+	sizes := map[int]int{}
+	active, maxActive := 0, 0
+
+	err := Ramp(context.Background(), 100*time.Millisecond, 3, 2, 4, func(ctx context.Context, sizeIdx int) error {
+		mu.Lock()
+		sizes[sizeIdx]++
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// fast transfers ramp up size to the
+	// largest one and then amount of streams
+	assert.Equal(t, 2, sizes[0])
+	assert.True(t, sizes[2] > 0, sizes)
+	assert.Equal(t, 4, maxActive)
+}
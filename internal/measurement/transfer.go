@@ -0,0 +1,138 @@
+package measurement
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Server represents details of a server used for measurement,
+// fields that aren't provided by measurement tool are left empty
+type Server struct {
+	ID       string
+	Name     string
+	Sponsor  string
+	Country  string
+	Host     string
+	URL      string
+	Lat      float64
+	Lon      float64
+	Distance float64
+	Latency  time.Duration
+}
+
+// ServerTransfer represents results of download/upload
+// measurement against single server
+type ServerTransfer struct {
+	Server   Server
+	Rate     BitRate
+	Bytes    int64
+	Duration time.Duration
+
+	// Spread is a spread of rates sampled during transfer
+	Spread Spread
+
+	// Err is set if measurement against server failed
+	Err error
+}
+
+// serverTransferJSON is a JSON representation of ServerTransfer,
+// error is represented by its message, since error values
+// can't be marshaled
+type serverTransferJSON struct {
+	Server   Server
+	Rate     BitRate
+	Bytes    int64
+	Duration time.Duration
+	Spread   Spread
+	Err      string `json:",omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, Err is
+// represented by its message
+func (t ServerTransfer) MarshalJSON() ([]byte, error) {
+	v := serverTransferJSON{
+		Server:   t.Server,
+		Rate:     t.Rate,
+		Bytes:    t.Bytes,
+		Duration: t.Duration,
+		Spread:   t.Spread,
+	}
+	if t.Err != nil {
+		v.Err = t.Err.Error()
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, Err is
+// restored from its message
+func (t *ServerTransfer) UnmarshalJSON(data []byte) error {
+	var v serverTransferJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*t = ServerTransfer{
+		Server:   v.Server,
+		Rate:     v.Rate,
+		Bytes:    v.Bytes,
+		Duration: v.Duration,
+		Spread:   v.Spread,
+	}
+	if v.Err != "" {
+		t.Err = errors.New(v.Err)
+	}
+
+	return nil
+}
+
+// Transfer represents detailed results of download/upload measurement,
+// Rate is aggregated from rates of succeeded servers and Spread
+// is a spread of their rates
+type Transfer struct {
+	Rate     BitRate
+	Spread   Spread
+	Bytes    int64
+	Duration time.Duration
+	Servers  []ServerTransfer
+}
+
+// ClientInfo represents details about client
+// as they are seen by measurement tool
+type ClientInfo struct {
+	IP  string
+	ISP string
+}
+
+// Errors returns errors of failed servers
+func (t Transfer) Errors() []error {
+	var errs []error
+	for _, server := range t.Servers {
+		if server.Err != nil {
+			errs = append(errs, server.Err)
+		}
+	}
+
+	return errs
+}
+
+// Err returns error describing failed servers of transfer
+func (t Transfer) Err() error {
+	return ServersError(t.Errors(), len(t.Servers))
+}
+
+// ServersError returns error describing failed servers out
+// of total servers, if only one server failed its error
+// is returned as is
+func ServersError(errs []error, total int) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	return fmt.Errorf("%d of %d servers failed: %w", len(errs), total, errs[0])
+}
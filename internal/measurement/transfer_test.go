@@ -0,0 +1,79 @@
+package measurement
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferErr(t *testing.T) {
+	tableTests := map[string]struct {
+		transfer    Transfer
+		expectedErr error
+	}{
+		"success-no-failures": {
+			transfer: Transfer{
+				Servers: []ServerTransfer{{}, {}},
+			},
+			expectedErr: nil,
+		},
+		"error-one-failure": {
+			transfer: Transfer{
+				Servers: []ServerTransfer{{}, {Err: errors.New("random error")}},
+			},
+			expectedErr: errors.New("random error"),
+		},
+		"error-multiple-failures": {
+			transfer: Transfer{
+				Servers: []ServerTransfer{
+					{},
+					{Err: errors.New("first error")},
+					{Err: errors.New("second error")},
+				},
+			},
+			expectedErr: errors.New("2 of 3 servers failed: first error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			err := testCase.transfer.Err()
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestServerTransferJSON(t *testing.T) {
+	transfer := ServerTransfer{
+		Server:   Server{URL: "https://example.com"},
+		Rate:     1000,
+		Bytes:    125,
+		Duration: time.Second,
+		Err:      errors.New("random error"),
+	}
+
+	data, err := json.Marshal(transfer)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Err":"random error"`)
+
+	var decoded ServerTransfer
+	err = json.Unmarshal(data, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, transfer.Server, decoded.Server)
+	assert.Equal(t, transfer.Rate, decoded.Rate)
+	assert.Equal(t, transfer.Bytes, decoded.Bytes)
+	assert.Equal(t, transfer.Duration, decoded.Duration)
+	assert.EqualError(t, decoded.Err, "random error")
+
+	data, err = json.Marshal(ServerTransfer{})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), `"Err"`)
+}
@@ -1,7 +1,6 @@
 package netflix
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/bejaneps/speedtest/internal/config"
@@ -20,7 +19,9 @@ type HTTPDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// NewClient is a constructor for Netflix's speedtest client
+// NewClient is a constructor for Netflix's speedtest client,
+// if token isn't set in config, then it will be fetched
+// from fast.com on first measurement
 func NewClient(conf *config.Config, doer HTTPDoer) (*Client, error) {
 	// in case if count is set to 0,
 	// better set it to 1, because 0 limit
@@ -29,12 +30,6 @@ func NewClient(conf *config.Config, doer HTTPDoer) (*Client, error) {
 		conf.ServerCount = 1
 	}
 
-	// TODO: remove this check when functionality
-	// for retrieving token is done
-	if conf.Token == "" {
-		return nil, errors.New("token is required for fast.com API")
-	}
-
 	cli := &Client{
 		conf: conf,
 		doer: doer,
@@ -0,0 +1,60 @@
+package netflix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+const clientInfoURL = "https://api.fast.com/netflix/speedtest/v2?https=true&token=%s&urlCount=1"
+
+type clientDetails struct {
+	IP  string `json:"ip"`
+	ISP string `json:"isp"`
+}
+
+type apiResponse struct {
+	Client clientDetails `json:"client"`
+}
+
+// ClientInfo requests from fast.com details about client
+func (c *Client) ClientInfo(ctx context.Context) (measurement.ClientInfo, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf(clientInfoURL, token),
+		nil,
+	)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	r := apiResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to json unmarshal response body: %w", err)
+	}
+
+	return measurement.ClientInfo{
+		IP:  r.Client.IP,
+		ISP: r.Client.ISP,
+	}, nil
+}
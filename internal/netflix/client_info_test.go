@@ -0,0 +1,80 @@
+package netflix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/netflix/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClientInfo(t *testing.T) {
+	tableTests := map[string]struct {
+		setup        func() *mocks.HTTPDoer
+		expectedErr  error
+		expectedInfo measurement.ClientInfo
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				body := `{"client":{"ip":"1.2.3.4","asn":"1234","isp":"Example ISP","location":{"city":"Amsterdam","country":"NL"}},` +
+					`"targets":[{"name":"https://example.com","url":"https://example.com"}]}`
+
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://api.fast.com/netflix/speedtest/v2?https=true&token=abc&urlCount=1"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(body)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedInfo: measurement.ClientInfo{
+				IP:  "1.2.3.4",
+				ISP: "Example ISP",
+			},
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://api.fast.com/netflix/speedtest/v2?https=true&token=abc&urlCount=1"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli, err := NewClient(
+				&config.Config{
+					ServerCount: 1,
+					Token:       "abc",
+				},
+				doer,
+			)
+			assert.NoError(t, err)
+
+			info, err := cli.ClientInfo(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedInfo, info)
+			}
+		})
+	}
+}
@@ -31,18 +31,19 @@ func TestClient(t *testing.T) {
 			serverCount: 0,
 			expectedURL: "https://api.fast.com/netflix/speedtest?https=true&token=abc&urlCount=1",
 		},
-		"fail-with-empty-token": {},
+		"fetch-token-with-empty-token": {
+			serverCount: 1,
+			expectedURL: "https://fast.com",
+		},
 	}
 
 	for testName, testCase := range tableTests {
 		testCase := testCase
 		t.Run(testName, func(t *testing.T) {
 			mockDoer := mocks.NewHTTPDoer(t)
-			if testCase.token != "" {
-				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == testCase.expectedURL
-				})).Return(nil, errors.New("random error"))
-			}
+			mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				return req.URL.String() == testCase.expectedURL
+			})).Return(nil, errors.New("random error"))
 
 			cli, err := netflix.NewClient(
 				&config.Config{
@@ -51,12 +52,10 @@ func TestClient(t *testing.T) {
 				},
 				mockDoer,
 			)
-			if testCase.token == "" {
-				assert.Equal(t, "token is required for fast.com API", err.Error())
-			} else {
-				_, err = cli.MeasureDownload(context.Background())
-				assert.Error(t, err)
-			}
+			assert.NoError(t, err)
+
+			_, err = cli.MeasureDownload(context.Background())
+			assert.Error(t, err)
 		})
 	}
 }
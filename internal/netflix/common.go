@@ -3,11 +3,17 @@ package netflix
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
 )
 
-const apiURL = "https://api.fast.com/netflix/speedtest?https=true&token=%s&urlCount=%d"
+const (
+	apiEndpoint = "https://api.fast.com/netflix/speedtest"
+	apiURL      = apiEndpoint + "?https=true&token=%s&urlCount=%d"
+)
 
 const bitsInByte = 8
 
@@ -15,13 +21,55 @@ type serverDetails struct {
 	URL string `json:"url"`
 }
 
+// server converts server details to measurement server
+func (s serverDetails) server() measurement.Server {
+	return measurement.Server{
+		URL: s.URL,
+	}
+}
+
 // getServersDetails requests from fast.com list of servers for
-// running download and upload tests
+// running download and upload tests, if token is rejected by
+// fast.com API, then it's refreshed and request is retried once,
+// failures are returned as server discovery errors
 func (c *Client) getServersDetails(ctx context.Context) ([]serverDetails, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, measurement.NewError(
+			measurement.ErrServerDiscovery, "", fastURL,
+			fmt.Errorf("failed to get token: %w", err),
+		)
+	}
+
+	servers, err := c.requestServersDetails(ctx, token)
+	if errors.Is(err, errUnauthorized) {
+		token, err = c.refreshToken(ctx)
+		if err != nil {
+			return nil, measurement.NewError(
+				measurement.ErrServerDiscovery, "", fastURL,
+				fmt.Errorf("failed to refresh token: %w", err),
+			)
+		}
+
+		servers, err = c.requestServersDetails(ctx, token)
+	}
+	if err != nil {
+		return nil, measurement.NewError(measurement.ErrServerDiscovery, "", apiEndpoint, err)
+	}
+	if len(servers) == 0 {
+		return nil, measurement.NewError(measurement.ErrNoServers, "", apiEndpoint, errors.New("server list is empty"))
+	}
+
+	return servers, nil
+}
+
+// requestServersDetails requests from fast.com list of servers
+// using provided token
+func (c *Client) requestServersDetails(ctx context.Context, token string) ([]serverDetails, error) {
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodGet,
-		fmt.Sprintf(apiURL, c.conf.Token, c.conf.ServerCount),
+		fmt.Sprintf(apiURL, token, c.conf.ServerCount),
 		nil,
 	)
 	if err != nil {
@@ -38,6 +86,18 @@ func (c *Client) getServersDetails(ctx context.Context) ([]serverDetails, error)
 		}
 	}()
 
+	if resp.StatusCode == http.StatusUnauthorized ||
+		resp.StatusCode == http.StatusForbidden {
+		return nil, &measurement.MeasurementError{
+			Kind:       measurement.ErrTokenInvalid,
+			StatusCode: resp.StatusCode,
+			Err:        errUnauthorized,
+		}
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, measurement.StatusError(resp.StatusCode)
+	}
+
 	servers := make([]serverDetails, 0, c.conf.ServerCount)
 	err = json.NewDecoder(resp.Body).Decode(&servers)
 	if err != nil {
@@ -46,3 +106,18 @@ func (c *Client) getServersDetails(ctx context.Context) ([]serverDetails, error)
 
 	return servers, nil
 }
+
+// Servers returns servers which would be used for measurement
+func (c *Client) Servers(ctx context.Context) ([]measurement.Server, error) {
+	details, err := c.getServersDetails(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make([]measurement.Server, 0, len(details))
+	for _, d := range details {
+		servers = append(servers, d.server())
+	}
+
+	return servers, nil
+}
@@ -57,7 +57,7 @@ func TestGetServersDetails(t *testing.T) {
 
 				return mockDoer
 			},
-			expectedErr:      errors.New("failed to send request: random error"),
+			expectedErr:      errors.New("server discovery failed on https://api.fast.com/netflix/speedtest: failed to send request: random error"),
 			expectedResponse: nil,
 		},
 	}
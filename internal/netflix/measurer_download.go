@@ -1,7 +1,6 @@
 package netflix
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -21,49 +20,94 @@ func (c *Client) MeasureDownload(ctx context.Context) (
 	downloadRate measurement.BitRate,
 	err error,
 ) {
-	servers, err := c.getServersDetails(ctx)
+	transfer, err := c.MeasureDownloadTransfer(ctx)
 	if err != nil {
 		return 0, err
 	}
 
+	return transfer.Rate, nil
+}
+
+// MeasureDownloadTransfer measures download speed per second using Netflix's fast.com API
+// and returns details of measurement including per server results, failed servers
+// are handled according to failure policy, in case of error results of all servers
+// are returned as well
+func (c *Client) MeasureDownloadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.getServersDetails(ctx)
+	if err != nil {
+		return measurement.Transfer{}, err
+	}
+
 	// run each calculation function in separate
 	// goroutine so it finishes faster
 	eg := errgroup.Group{}
-	downloadRateChan := make(chan float64, len(servers))
+	resultChan := make(chan measurement.ServerTransfer, len(servers))
 
-	avgDownloadRate := 0.0
+	start := time.Now()
 	for _, server := range servers {
-		url := server.URL
+		server := server
 
 		eg.Go(func() error {
-			downloadRate, err := defaultDownloadFunc(ctx, c.doer, url)
-			if err != nil {
-				return err
+			serverStart := time.Now()
+			meter := c.conf.NewMeter()
+			stopProgress := c.conf.WatchProgress(meter, measurement.PhaseDownload, server.URL)
+			downloadRate, size, err := defaultDownloadFunc(ctx, c.doer, server.URL, meter)
+			stopProgress()
+			resultChan <- measurement.ServerTransfer{
+				Server:   server.server(),
+				Rate:     measurement.BitRate(downloadRate),
+				Bytes:    size,
+				Duration: time.Since(serverStart),
+				Spread:   meter.SampleSpread(),
+				Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseDownload, server.URL, err),
 			}
-			downloadRateChan <- downloadRate
+			// failed servers are handled
+			// after all servers finished
 			return nil
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		close(downloadRateChan)
-
-		return 0, err
-	}
-	close(downloadRateChan)
+	_ = eg.Wait()
+	close(resultChan)
+	transfer.Duration = time.Since(start)
 
 	// for each server calculate download speeds
-	// and take average number
-	for downloadRate := range downloadRateChan {
-		avgDownloadRate += downloadRate
+	// and aggregate rates of succeeded ones
+	rates := make([]measurement.BitRate, 0, len(servers))
+	failures := 0
+	for result := range resultChan {
+		transfer.Servers = append(transfer.Servers, result)
+		if result.Err != nil {
+			failures++
+			continue
+		}
+
+		rates = append(rates, result.Rate)
+		transfer.Bytes += result.Bytes
+	}
+	if !c.conf.Tolerates(failures, len(servers)) {
+		return transfer, transfer.Err()
 	}
-	avgDownloadRate = avgDownloadRate / float64(len(servers))
 
-	return measurement.BitRate(avgDownloadRate), nil
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers(rates)
+
+	return transfer, nil
 }
 
-// download downloads content from provided url and calculates
-// amount of bits downloaded
-func download(ctx context.Context, doer HTTPDoer, url string) (float64, error) {
+// download downloads content from provided url to meter and returns
+// steady state amount of bits downloaded per second, timing covers
+// the whole body transfer, it also returns amount of downloaded bytes
+func download(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	meter *measurement.Meter,
+) (float64, int64, error) {
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodGet,
@@ -71,26 +115,32 @@ func download(ctx context.Context, doer HTTPDoer, url string) (float64, error) {
 		nil,
 	)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
+		return 0, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	start := time.Now()
 	resp, err := doer.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("failed to send request: %w", err)
+		return 0, 0, fmt.Errorf("failed to send request: %w", err)
 	}
-	end := time.Now()
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			fmt.Printf("failed to close response body: %v\n", err)
 		}
 	}()
 
-	buf := &bytes.Buffer{}
-	b, err := io.Copy(buf, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	// body is streamed to meter, so large
+	// files are never held in memory
+	n, err := io.Copy(meter, resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("failed to copy response body: %v\n", err)
+		return 0, n, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	if resp.ContentLength > 0 && n < resp.ContentLength {
+		return 0, n, fmt.Errorf("failed to copy response body: %w", io.ErrUnexpectedEOF)
 	}
 
-	return float64(b*bitsInByte) / end.Sub(start).Seconds(), nil
+	return float64(meter.SampledRate()), n, nil
 }
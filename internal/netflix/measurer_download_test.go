@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
 	"github.com/bejaneps/speedtest/internal/ookla/mocks"
 	"github.com/bejaneps/speedtest/internal/pkg/random"
 	"github.com/stretchr/testify/assert"
@@ -48,8 +49,8 @@ func TestMeasureDownload(t *testing.T) {
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string) (float64, error) {
-					return 100, nil
+				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, meter *measurement.Meter) (float64, int64, error) {
+					return 100, 100, nil
 				}
 
 				return mockDoer
@@ -77,15 +78,15 @@ func TestMeasureDownload(t *testing.T) {
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string) (float64, error) {
-					return 0, errors.New("random error")
+				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, meter *measurement.Meter) (float64, int64, error) {
+					return 0, 0, errors.New("random error")
 				}
 
 				return mockDoer
 			},
 			serverCount: 1,
 			token:       "abc",
-			expectedErr: errors.New("random error"),
+			expectedErr: errors.New("download: transfer failed on https://example.com: random error"),
 		},
 	}
 
@@ -128,15 +129,48 @@ func TestDownload(t *testing.T) {
 					On("Do", mock.MatchedBy(func(req *http.Request) bool {
 						return req.URL.String() == "https://example.com"
 					})).
-					WaitUntil(time.After(1*time.Second)).
+					After(time.Second).
 					Return(&http.Response{
-						Body: io.NopCloser(bytes.NewBufferString(random.String(100_000))), // download 100_000 bytes in 1 second
+						Body: io.NopCloser(bytes.NewBufferString(random.String(100_000))), // headers take 1 second since request is sent
 					}, nil)
 
 				return mockDoer
 			},
 			expectedErr: nil,
 		},
+		"success-slow-body": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.
+					On("Do", mock.MatchedBy(func(req *http.Request) bool {
+						return req.URL.String() == "https://example.com"
+					})).
+					Return(&http.Response{
+						Body: io.NopCloser(&slowReader{
+							r:     bytes.NewBufferString(random.String(100_000)),
+							chunk: 10_000,
+							delay: time.Second / 10,
+						}), // headers are received instantly, but body takes 1 second
+					}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+		},
+		"error-from-truncated-body": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com"
+				})).Return(&http.Response{
+					ContentLength: 10,
+					Body:          io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to copy response body: unexpected EOF"),
+		},
 		"error-from-doer-fail": {
 			setup: func() *mocks.HTTPDoer {
 				mockDoer := new(mocks.HTTPDoer)
@@ -155,14 +189,92 @@ func TestDownload(t *testing.T) {
 		t.Run(testName, func(t *testing.T) {
 			doer := testCase.setup()
 
-			rate, err := download(context.Background(), doer, "https://example.com")
+			rate, n, err := download(context.Background(), doer, "https://example.com", measurement.NewMeter(0, 0, 0, nil))
 			if testCase.expectedErr != nil {
 				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, int64(100_000), n)
 
-				assert.True(t, rate > 799_000 && rate < 810_000, rate)
+				// 800_000 bits take at least 1 second after meter
+				// is started, so rate can't be higher than that
+				assert.True(t, rate > 0 && rate <= 800_000, rate)
 			}
 		})
 	}
 }
+
+func TestMeasureDownloadTransfer(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+	})
+
+	buf := &bytes.Buffer{}
+	servers := []serverDetails{
+		{
+			URL: "https://first.com",
+		},
+		{
+			URL: "https://second.com",
+		},
+	}
+	err := json.NewEncoder(buf).Encode(&servers)
+	assert.NoError(t, err)
+
+	mockDoer := mocks.NewHTTPDoer(t)
+	mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=abc&urlCount=2"
+	})).Return(&http.Response{
+		Body: io.NopCloser(buf),
+	}, nil)
+
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, meter *measurement.Meter) (float64, int64, error) {
+		if url == "https://second.com" {
+			return 0, 0, errors.New("random error")
+		}
+		return 100, 1000, nil
+	}
+
+	cli, err := NewClient(
+		&config.Config{
+			ServerCount: 2,
+			Token:       "abc",
+		},
+		mockDoer,
+	)
+	assert.NoError(t, err)
+
+	transfer, err := cli.MeasureDownloadTransfer(context.Background())
+	assert.Equal(t, "download: transfer failed on https://second.com: random error", err.Error())
+	assert.True(t, errors.Is(err, measurement.ErrTransfer))
+	assert.Len(t, transfer.Servers, 2)
+	assert.Equal(t, int64(1000), transfer.Bytes)
+
+	for _, server := range transfer.Servers {
+		if server.Server.URL == "https://second.com" {
+			assert.Equal(t, "download: transfer failed on https://second.com: random error", server.Err.Error())
+		} else {
+			assert.NoError(t, server.Err)
+			assert.Equal(t, 100, int(server.Rate))
+			assert.Equal(t, int64(1000), server.Bytes)
+		}
+	}
+}
+
+// slowReader reads from underlying reader
+// in chunks with delay between them
+type slowReader struct {
+	r     io.Reader
+	chunk int
+	delay time.Duration
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+
+	if len(p) > s.chunk {
+		p = p[:s.chunk]
+	}
+
+	return s.r.Read(p)
+}
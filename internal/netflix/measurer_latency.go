@@ -0,0 +1,147 @@
+package netflix
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	stdURL "net/url"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	pingURLPathSuffix = "/range/0-0"
+
+	pingCount = 10
+)
+
+// defaultPingFunc is a variable to wrap ping function
+// for deterministic results
+var defaultPingFunc = ping
+
+// MeasureLatency measures latency and jitter using Netflix's fast.com API
+func (c *Client) MeasureLatency(ctx context.Context) (
+	latency measurement.Latency,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.getServersDetails(ctx)
+	if err != nil {
+		return measurement.Latency{}, err
+	}
+
+	// run each calculation function in separate
+	// goroutine so it finishes faster
+	eg := errgroup.Group{}
+	latencyChan := make(chan measurement.Latency, len(servers))
+	errChan := make(chan error, len(servers))
+
+	for _, server := range servers {
+		url := server.URL
+
+		eg.Go(func() error {
+			latency, err := c.measureLatency(ctx, url)
+			if err != nil {
+				// failed servers are handled
+				// after all servers finished
+				errChan <- err
+				return nil
+			}
+			latencyChan <- latency
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	close(latencyChan)
+	close(errChan)
+
+	var errs []error
+	for err := range errChan {
+		errs = append(errs, err)
+	}
+	if !c.conf.Tolerates(len(errs), len(servers)) {
+		return measurement.Latency{}, measurement.ServersError(errs, len(servers))
+	}
+
+	// for each server calculate latency
+	// and take average numbers of succeeded ones
+	latencies := make([]measurement.Latency, 0, len(servers))
+	for latency := range latencyChan {
+		latencies = append(latencies, latency)
+	}
+
+	return measurement.AverageLatency(latencies), nil
+}
+
+// measureLatency sends n sequential pings to provided url
+// and calculates latency statistics from round trip times
+func (c *Client) measureLatency(ctx context.Context, url string) (measurement.Latency, error) {
+	// the first ping sets up connection, so its round
+	// trip time includes handshakes and is discarded
+	if _, err := defaultPingFunc(ctx, c.doer, url); err != nil {
+		return measurement.Latency{}, measurement.NewError(measurement.ErrTransfer, measurement.PhaseLatency, url, err)
+	}
+
+	samples := make([]time.Duration, 0, pingCount)
+	start := time.Now()
+	for i := 0; i < pingCount; i++ {
+		rtt, err := defaultPingFunc(ctx, c.doer, url)
+		if err != nil {
+			return measurement.Latency{}, measurement.NewError(measurement.ErrTransfer, measurement.PhaseLatency, url, err)
+		}
+
+		samples = append(samples, rtt)
+		c.conf.ReportLatency(url, rtt, time.Since(start))
+	}
+
+	return measurement.NewLatency(samples), nil
+}
+
+// ping requests empty range of content from provided url
+// and returns round trip time
+func ping(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+	pingURL, err := stdURL.Parse(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse url: %w", err)
+	}
+	pingURL.Path += pingURLPathSuffix
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		pingURL.String(),
+		nil,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := doer.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	end := time.Now()
+
+	return end.Sub(start), nil
+}
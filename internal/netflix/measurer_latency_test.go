@@ -0,0 +1,206 @@
+package netflix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/netflix/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureLatency(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	tableTests := map[string]struct {
+		setup           func() *mocks.HTTPDoer
+		serverCount     int
+		token           string
+		expectedErr     error
+		expectedLatency measurement.Latency
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{
+						URL: "https://example.com/speedtest",
+					},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=abc&urlCount=1"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					return 10 * time.Millisecond, nil
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			token:       "abc",
+			expectedErr: nil,
+			expectedLatency: measurement.Latency{
+				Min: 10 * time.Millisecond,
+				Avg: 10 * time.Millisecond,
+				Max: 10 * time.Millisecond,
+			},
+		},
+		"success-warm-up-discarded": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{
+						URL: "https://example.com/speedtest",
+					},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=abc&urlCount=1"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				pings := 0
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					pings++
+					if pings == 1 {
+						return 500 * time.Millisecond, nil
+					}
+					return 10 * time.Millisecond, nil
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			token:       "abc",
+			expectedErr: nil,
+			expectedLatency: measurement.Latency{
+				Min: 10 * time.Millisecond,
+				Avg: 10 * time.Millisecond,
+				Max: 10 * time.Millisecond,
+			},
+		},
+		"error-from-ping-fail": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{
+						URL: "https://example.com/speedtest",
+					},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=abc&urlCount=1"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					return 0, errors.New("random error")
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			token:       "abc",
+			expectedErr: errors.New("latency: transfer failed on https://example.com/speedtest: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli, err := NewClient(
+				&config.Config{
+					ServerCount: testCase.serverCount,
+					Token:       testCase.token,
+				},
+				doer,
+			)
+			assert.NoError(t, err)
+
+			latency, err := cli.MeasureLatency(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedLatency, latency)
+			}
+		})
+	}
+}
+
+func TestPing(t *testing.T) {
+	tableTests := map[string]struct {
+		setup       func() *mocks.HTTPDoer
+		expectedErr error
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.
+					On("Do", mock.MatchedBy(func(req *http.Request) bool {
+						return req.URL.String() == "https://example.com/speedtest/range/0-0?c=nl&t=abc"
+					})).
+					WaitUntil(time.After(time.Second/10)).
+					Return(&http.Response{
+						Body: io.NopCloser(bytes.NewBufferString("")),
+					}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/speedtest/range/0-0?c=nl&t=abc"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			rtt, err := ping(context.Background(), doer, "https://example.com/speedtest?c=nl&t=abc")
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, rtt >= time.Second/20 && rtt < time.Second/5, rtt)
+			}
+		})
+	}
+}
@@ -1,15 +1,156 @@
 package netflix
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
 
 	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/pkg/random"
+	"golang.org/x/sync/errgroup"
 )
 
+const uploadSize = 2_000_000
+
+// defaultUploadFunc is a variable to wrap upload function
+// for deterministic results
+var defaultUploadFunc = upload
+
 // MeasureUpload measures upload speed per second using Netflix's fast.com API
 func (c *Client) MeasureUpload(ctx context.Context) (
 	uploadRate measurement.BitRate,
 	err error,
 ) {
-	return 0, nil
+	transfer, err := c.MeasureUploadTransfer(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return transfer.Rate, nil
+}
+
+// MeasureUploadTransfer measures upload speed per second using Netflix's fast.com API
+// and returns details of measurement including per server results, failed servers
+// are handled according to failure policy, in case of error results of all servers
+// are returned as well
+func (c *Client) MeasureUploadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.getServersDetails(ctx)
+	if err != nil {
+		return measurement.Transfer{}, err
+	}
+
+	// payload is generated once before any meter
+	// is started, so its generation isn't measured
+	content := []byte(random.String(uploadSize))
+
+	// run each calculation function in separate
+	// goroutine so it finishes faster
+	eg := errgroup.Group{}
+	resultChan := make(chan measurement.ServerTransfer, len(servers))
+
+	start := time.Now()
+	for _, server := range servers {
+		server := server
+
+		eg.Go(func() error {
+			serverStart := time.Now()
+			meter := c.conf.NewMeter()
+			stopProgress := c.conf.WatchProgress(meter, measurement.PhaseUpload, server.URL)
+			uploadRate, size, err := defaultUploadFunc(ctx, c.doer, server.URL, content, meter)
+			stopProgress()
+			resultChan <- measurement.ServerTransfer{
+				Server:   server.server(),
+				Rate:     measurement.BitRate(uploadRate),
+				Bytes:    size,
+				Duration: time.Since(serverStart),
+				Spread:   meter.SampleSpread(),
+				Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseUpload, server.URL, err),
+			}
+			// failed servers are handled
+			// after all servers finished
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	close(resultChan)
+	transfer.Duration = time.Since(start)
+
+	// for each server calculate upload speeds
+	// and aggregate rates of succeeded ones
+	rates := make([]measurement.BitRate, 0, len(servers))
+	failures := 0
+	for result := range resultChan {
+		transfer.Servers = append(transfer.Servers, result)
+		if result.Err != nil {
+			failures++
+			continue
+		}
+
+		rates = append(rates, result.Rate)
+		transfer.Bytes += result.Bytes
+	}
+	if !c.conf.Tolerates(failures, len(servers)) {
+		return transfer, transfer.Err()
+	}
+
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers(rates)
+
+	return transfer, nil
+}
+
+// upload uploads provided content to provided url, counting sent bytes
+// in meter, and returns steady state amount of bits uploaded per second,
+// it also returns amount of uploaded bytes, content is only read, so
+// it can be shared by concurrent uploads
+func upload(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) (float64, int64, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		url,
+		meter.Reader(bytes.NewReader(content)),
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.ContentLength = int64(len(content))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	meter.Stop()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to copy response body: %w", err)
+	}
+
+	return float64(meter.SampledRate()), meter.Bytes(), nil
 }
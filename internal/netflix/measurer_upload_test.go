@@ -0,0 +1,172 @@
+package netflix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/netflix/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureUpload(t *testing.T) {
+	t.Cleanup(func() {
+		defaultUploadFunc = upload
+	})
+
+	tableTests := map[string]struct {
+		setup       func() *mocks.HTTPDoer
+		serverCount int
+		token       string
+		expectedErr error
+	}{
+		"success-100-mbit": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{
+						URL: "https://example.com",
+					},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=abc&urlCount=1"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (float64, int64, error) {
+					return 100, 100, nil
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			token:       "abc",
+			expectedErr: nil,
+		},
+		"error-from-upload-fail": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{
+						URL: "https://example.com",
+					},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=abc&urlCount=1"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (float64, int64, error) {
+					return 0, 0, errors.New("random error")
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			token:       "abc",
+			expectedErr: errors.New("upload: transfer failed on https://example.com: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli, err := NewClient(
+				&config.Config{
+					ServerCount: testCase.serverCount,
+					Token:       testCase.token,
+				},
+				doer,
+			)
+			assert.NoError(t, err)
+
+			rate, err := cli.MeasureUpload(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+				assert.Equal(t, 0, int(rate))
+			} else {
+				assert.NoError(t, err)
+
+				assert.True(t, rate == 100, rate)
+			}
+		})
+	}
+}
+
+func TestUpload(t *testing.T) {
+	tableTests := map[string]struct {
+		setup       func() *mocks.HTTPDoer
+		expectedErr error
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.
+					On("Do", mock.MatchedBy(func(req *http.Request) bool {
+						b, err := io.ReadAll(req.Body)
+						if err != nil {
+							return false
+						}
+						return req.Method == http.MethodPost &&
+							len(b) == uploadSize &&
+							req.URL.String() == "https://example.com"
+					})).
+					WaitUntil(time.After(1*time.Second)).
+					Return(&http.Response{
+						Body: io.NopCloser(bytes.NewBufferString("")),
+					}, nil) // upload 2_000_000 bytes in 1 second
+
+				return mockDoer
+			},
+			expectedErr: nil,
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			content := make([]byte, uploadSize)
+			rate, _, err := upload(context.Background(), doer, "https://example.com", content, measurement.NewMeter(0, 0, 0, nil))
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+
+				assert.True(t, rate > 15_000_000 && rate < 17_000_000, rate)
+			}
+		})
+	}
+}
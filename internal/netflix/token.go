@@ -0,0 +1,130 @@
+package netflix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+)
+
+const fastURL = "https://fast.com"
+
+var (
+	scriptRegexp = regexp.MustCompile(`<script src="(/app-[a-zA-Z0-9]+\.js)">`)
+	tokenRegexp  = regexp.MustCompile(`token:"([a-zA-Z0-9]+)"`)
+)
+
+// errUnauthorized is returned when fast.com API rejects token
+var errUnauthorized = errors.New("token was rejected by fast.com API")
+
+// cachedToken stores token fetched from fast.com,
+// it's shared between all clients for the process lifetime
+var cachedToken = &tokenCache{}
+
+// defaultFetchTokenFunc is a variable to wrap fetchToken function
+// for deterministic results
+var defaultFetchTokenFunc = fetchToken
+
+type tokenCache struct {
+	mu    sync.Mutex
+	token string
+}
+
+// token returns token for fast.com API, if token wasn't provided
+// in config, then it's fetched from fast.com and cached
+func (c *Client) token(ctx context.Context) (string, error) {
+	cachedToken.mu.Lock()
+	defer cachedToken.mu.Unlock()
+
+	if c.conf.Token != "" {
+		return c.conf.Token, nil
+	}
+
+	if cachedToken.token != "" {
+		return cachedToken.token, nil
+	}
+
+	token, err := defaultFetchTokenFunc(ctx, c.doer)
+	if err != nil {
+		return "", err
+	}
+	cachedToken.token = token
+
+	return token, nil
+}
+
+// refreshToken fetches new token from fast.com and replaces
+// cached token, token provided in config is dropped as well,
+// because it was rejected by fast.com API
+func (c *Client) refreshToken(ctx context.Context) (string, error) {
+	cachedToken.mu.Lock()
+	defer cachedToken.mu.Unlock()
+
+	token, err := defaultFetchTokenFunc(ctx, c.doer)
+	if err != nil {
+		return "", err
+	}
+	cachedToken.token = token
+	c.conf.Token = ""
+
+	return token, nil
+}
+
+// fetchToken scrapes fast.com landing page, finds its app script
+// and extracts token for fast.com API from it
+func fetchToken(ctx context.Context, doer HTTPDoer) (string, error) {
+	page, err := get(ctx, doer, fastURL)
+	if err != nil {
+		return "", err
+	}
+
+	match := scriptRegexp.FindSubmatch(page)
+	if match == nil {
+		return "", errors.New("failed to find app script in fast.com page")
+	}
+
+	script, err := get(ctx, doer, fastURL+string(match[1]))
+	if err != nil {
+		return "", err
+	}
+
+	match = tokenRegexp.FindSubmatch(script)
+	if match == nil {
+		return "", errors.New("failed to find token in fast.com app script")
+	}
+
+	return string(match[1]), nil
+}
+
+// get requests provided url and returns response body
+func get(ctx context.Context, doer HTTPDoer, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		url,
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return b, nil
+}
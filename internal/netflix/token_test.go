@@ -0,0 +1,188 @@
+package netflix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/netflix/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFetchToken(t *testing.T) {
+	tableTests := map[string]struct {
+		setup         func() *mocks.HTTPDoer
+		expectedErr   error
+		expectedToken string
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://fast.com"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`<html><script src="/app-1a2b3c.js"></script></html>`)),
+				}, nil)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://fast.com/app-1a2b3c.js"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`var a={https:!0,token:"YXNkZmFzZGxm",urlCount:5}`)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   nil,
+			expectedToken: "YXNkZmFzZGxm",
+		},
+		"error-from-missing-script": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://fast.com"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`<html></html>`)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to find app script in fast.com page"),
+		},
+		"error-from-missing-token": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://fast.com"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`<html><script src="/app-1a2b3c.js"></script></html>`)),
+				}, nil)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://fast.com/app-1a2b3c.js"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(`var a={}`)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to find token in fast.com app script"),
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://fast.com"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			token, err := fetchToken(context.Background(), doer)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedToken, token)
+			}
+		})
+	}
+}
+
+func TestGetServersDetailsRefreshToken(t *testing.T) {
+	t.Cleanup(func() {
+		defaultFetchTokenFunc = fetchToken
+		cachedToken.token = ""
+	})
+
+	buf := &bytes.Buffer{}
+	servers := []serverDetails{
+		{
+			URL: "https://example.com",
+		},
+	}
+	err := json.NewEncoder(buf).Encode(&servers)
+	assert.NoError(t, err)
+
+	mockDoer := mocks.NewHTTPDoer(t)
+	mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=stale&urlCount=1"
+	})).Return(&http.Response{
+		StatusCode: http.StatusForbidden,
+		Body:       io.NopCloser(&bytes.Buffer{}),
+	}, nil)
+	mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://api.fast.com/netflix/speedtest?https=true&token=fresh&urlCount=1"
+	})).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(buf),
+	}, nil)
+
+	defaultFetchTokenFunc = func(ctx context.Context, doer HTTPDoer) (string, error) {
+		return "fresh", nil
+	}
+
+	cli, err := NewClient(
+		&config.Config{
+			ServerCount: 1,
+			Token:       "stale",
+		},
+		mockDoer,
+	)
+	assert.NoError(t, err)
+
+	details, err := cli.getServersDetails(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, servers, details)
+
+	token, err := cli.token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "fresh", token)
+}
+
+func TestGetServersDetailsTokenInvalid(t *testing.T) {
+	t.Cleanup(func() {
+		defaultFetchTokenFunc = fetchToken
+		cachedToken.token = ""
+	})
+
+	mockDoer := mocks.NewHTTPDoer(t)
+	mockDoer.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusForbidden,
+		Body:       io.NopCloser(&bytes.Buffer{}),
+	}, nil)
+
+	defaultFetchTokenFunc = func(ctx context.Context, doer HTTPDoer) (string, error) {
+		return "fresh", nil
+	}
+
+	cli, err := NewClient(
+		&config.Config{
+			ServerCount: 1,
+			Token:       "stale",
+		},
+		mockDoer,
+	)
+	assert.NoError(t, err)
+
+	_, err = cli.getServersDetails(context.Background())
+	assert.Equal(t, "token is invalid on https://api.fast.com/netflix/speedtest: token was rejected by fast.com API", err.Error())
+	assert.True(t, errors.Is(err, measurement.ErrTokenInvalid))
+
+	var merr *measurement.MeasurementError
+	if assert.True(t, errors.As(err, &merr)) {
+		assert.Equal(t, http.StatusForbidden, merr.StatusCode)
+	}
+}
@@ -2,6 +2,7 @@ package ookla
 
 import (
 	"net/http"
+	"sync"
 
 	"github.com/bejaneps/speedtest/internal/config"
 )
@@ -10,6 +11,11 @@ import (
 type Client struct {
 	conf *config.Config
 	doer HTTPDoer
+
+	// servers are selected once and reused by all
+	// measurement phases until any of them fails
+	serversMu sync.Mutex
+	servers   []serverDetails
 }
 
 // HTTPDoer is used for mocking purposes
@@ -0,0 +1,55 @@
+package ookla
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+const configURL = "https://www.speedtest.net/speedtest-config.php"
+
+type clientDetails struct {
+	IP  string `xml:"ip,attr"`
+	ISP string `xml:"isp,attr"`
+}
+
+type settings struct {
+	Client clientDetails `xml:"client"`
+}
+
+// ClientInfo requests from speedtest.net details about client
+func (c *Client) ClientInfo(ctx context.Context) (measurement.ClientInfo, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		configURL,
+		nil,
+	)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Printf("failed to close response body: %v\n", err)
+		}
+	}()
+
+	s := settings{}
+	err = xml.NewDecoder(resp.Body).Decode(&s)
+	if err != nil {
+		return measurement.ClientInfo{}, fmt.Errorf("failed to xml unmarshal response body: %w", err)
+	}
+
+	return measurement.ClientInfo{
+		IP:  s.Client.IP,
+		ISP: s.Client.ISP,
+	}, nil
+}
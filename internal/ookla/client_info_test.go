@@ -0,0 +1,78 @@
+package ookla
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/ookla/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClientInfo(t *testing.T) {
+	tableTests := map[string]struct {
+		setup        func() *mocks.HTTPDoer
+		expectedErr  error
+		expectedInfo measurement.ClientInfo
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				body := `<?xml version="1.0" encoding="UTF-8"?>` +
+					`<settings><client ip="1.2.3.4" lat="52.3" lon="4.9" isp="Example ISP" isprating="3.7"/></settings>`
+
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/speedtest-config.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(body)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedInfo: measurement.ClientInfo{
+				IP:  "1.2.3.4",
+				ISP: "Example ISP",
+			},
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/speedtest-config.php"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(
+				&config.Config{
+					ServerCount: 1,
+				},
+				doer,
+			)
+
+			info, err := cli.ClientInfo(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedInfo, info)
+			}
+		})
+	}
+}
@@ -22,11 +22,15 @@ func TestClient(t *testing.T) {
 	}{
 		"correct-client-5-server-count": {
 			serverCount: 5,
-			expectedURL: "https://www.speedtest.net/api/js/servers?engine=js&limit=5",
+			expectedURL: "https://www.speedtest.net/api/js/servers?engine=js&limit=15",
+		},
+		"correct-client-20-server-count": {
+			serverCount: 20,
+			expectedURL: "https://www.speedtest.net/api/js/servers?engine=js&limit=40",
 		},
 		"correct-client-0-server-count": {
 			serverCount: 0,
-			expectedURL: "https://www.speedtest.net/api/js/servers?engine=js&limit=1",
+			expectedURL: "https://www.speedtest.net/api/js/servers?engine=js&limit=11",
 		},
 	}
 
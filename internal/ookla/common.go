@@ -3,28 +3,187 @@ package ookla
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"golang.org/x/sync/errgroup"
 )
 
-const apiURL = "https://www.speedtest.net/api/js/servers?engine=js&limit=%d"
+const apiURL = "https://www.speedtest.net/api/js/servers?engine=js"
 
 const (
 	bitsInByte = 8
 	workload   = 4
+
+	// minCandidateCount is a minimum amount of extra servers
+	// requested from speedtest.net, closest of them
+	// are selected for measurement
+	minCandidateCount  = 10
+	candidatePingCount = 3
 )
 
 type serverDetails struct {
-	URL string `json:"url"`
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Sponsor  string  `json:"sponsor"`
+	Country  string  `json:"country"`
+	Lat      float64 `json:"lat,string"`
+	Lon      float64 `json:"lon,string"`
+	Distance float64 `json:"distance"`
+	Host     string  `json:"host"`
+	URL      string  `json:"url"`
+
+	// latency is measured by client
+	// while selecting closest servers
+	latency time.Duration
+}
+
+// server converts server details to measurement server
+func (s serverDetails) server() measurement.Server {
+	return measurement.Server{
+		ID:       s.ID,
+		Name:     s.Name,
+		Sponsor:  s.Sponsor,
+		Country:  s.Country,
+		Host:     s.Host,
+		URL:      s.URL,
+		Lat:      s.Lat,
+		Lon:      s.Lon,
+		Distance: s.Distance,
+		Latency:  s.latency,
+	}
+}
+
+// selectServers returns servers with the lowest latency, they are
+// selected on the first call and reused by the following ones, until
+// they're reset
+func (c *Client) selectServers(ctx context.Context) ([]serverDetails, error) {
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+
+	if c.servers != nil {
+		return c.servers, nil
+	}
+
+	servers, err := c.rankServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.servers = servers
+
+	return servers, nil
 }
 
-// getServersDetails requests from speedtest.net list of servers for
-// running download and upload tests
+// resetServers discards selected servers, so failed server
+// isn't reused and servers are selected again by next call
+func (c *Client) resetServers() {
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+
+	c.servers = nil
+}
+
+// rankServers requests candidate servers from speedtest.net,
+// pings each of them and returns servers with the lowest latency
+func (c *Client) rankServers(ctx context.Context) ([]serverDetails, error) {
+	candidates, err := c.getServersDetails(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eg := errgroup.Group{}
+	mu := sync.Mutex{}
+	servers := make([]serverDetails, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		candidate := candidate
+		url := strings.TrimSuffix(candidate.URL, downloadServerURLSuffix)
+
+		eg.Go(func() error {
+			var rttSum time.Duration
+			for i := 0; i < candidatePingCount; i++ {
+				rtt, err := defaultPingFunc(ctx, c.doer, url)
+				if err != nil {
+					// unreachable server is
+					// just not selected
+					return nil
+				}
+				rttSum += rtt
+			}
+			candidate.latency = rttSum / candidatePingCount
+
+			mu.Lock()
+			servers = append(servers, candidate)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	if len(servers) == 0 {
+		return nil, measurement.NewError(
+			measurement.ErrNoServers, "", "",
+			errors.New("failed to reach any of candidate servers"),
+		)
+	}
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].latency < servers[j].latency
+	})
+	if len(servers) > c.conf.ServerCount {
+		servers = servers[:c.conf.ServerCount]
+	}
+
+	return servers, nil
+}
+
+// getServersDetails requests from speedtest.net list of candidate
+// servers for running download and upload tests, failures are
+// returned as server discovery errors
 func (c *Client) getServersDetails(ctx context.Context) ([]serverDetails, error) {
+	listURL := apiURL
+	if c.conf.ServerListURL != "" {
+		listURL = c.conf.ServerListURL
+	}
+
+	servers, err := c.requestServersDetails(ctx, listURL)
+	if err != nil {
+		return nil, measurement.NewError(measurement.ErrServerDiscovery, "", listURL, err)
+	}
+	if len(servers) == 0 {
+		return nil, measurement.NewError(measurement.ErrNoServers, "", listURL, errors.New("server list is empty"))
+	}
+
+	return servers, nil
+}
+
+// requestServersDetails requests list of candidate servers
+// from provided url, amount of candidates is limited by
+// limit query parameter
+func (c *Client) requestServersDetails(ctx context.Context, listURL string) ([]serverDetails, error) {
+	candidateCount := candidateCount(c.conf.ServerCount)
+
+	u, err := url.Parse(listURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse server list url: %w", err)
+	}
+	query := u.Query()
+	query.Set("limit", strconv.Itoa(candidateCount))
+	u.RawQuery = query.Encode()
+
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodGet,
-		fmt.Sprintf(apiURL, c.conf.ServerCount),
+		u.String(),
 		nil,
 	)
 	if err != nil {
@@ -41,7 +200,11 @@ func (c *Client) getServersDetails(ctx context.Context) ([]serverDetails, error)
 		}
 	}()
 
-	servers := make([]serverDetails, 0, c.conf.ServerCount)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, measurement.StatusError(resp.StatusCode)
+	}
+
+	servers := make([]serverDetails, 0, candidateCount)
 	err = json.NewDecoder(resp.Body).Decode(&servers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to json unmarshal response body: %w", err)
@@ -49,3 +212,30 @@ func (c *Client) getServersDetails(ctx context.Context) ([]serverDetails, error)
 
 	return servers, nil
 }
+
+// candidateCount returns amount of candidates requested for
+// selecting serverCount servers, there are always more
+// candidates than needed, so closest of them can be picked
+func candidateCount(serverCount int) int {
+	if 2*serverCount > serverCount+minCandidateCount {
+		return 2 * serverCount
+	}
+
+	return serverCount + minCandidateCount
+}
+
+// Servers returns servers which would be used for measurement,
+// sorted by latency
+func (c *Client) Servers(ctx context.Context) ([]measurement.Server, error) {
+	details, err := c.selectServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make([]measurement.Server, 0, len(details))
+	for _, d := range details {
+		servers = append(servers, d.server())
+	}
+
+	return servers, nil
+}
@@ -7,9 +7,12 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
 	"github.com/bejaneps/speedtest/internal/ookla/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,7 +37,7 @@ func TestGetServersDetails(t *testing.T) {
 
 				mockDoer := new(mocks.HTTPDoer)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1"
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
 				})).Return(&http.Response{
 					Body: io.NopCloser(buf),
 				}, nil)
@@ -48,16 +51,46 @@ func TestGetServersDetails(t *testing.T) {
 				},
 			},
 		},
+		"success-full-metadata": {
+			setup: func() *mocks.HTTPDoer {
+				body := `[{"url":"http://speedtest.example.com:8080/speedtest/upload.php","lat":"52.3667","lon":"4.9000",` +
+					`"distance":5,"name":"Amsterdam","country":"Netherlands","cc":"NL","sponsor":"Example",` +
+					`"id":"12345","preferred":0,"https_functional":1,"host":"speedtest.example.com:8080"}]`
+
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString(body)),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+			expectedResponse: []serverDetails{
+				{
+					ID:       "12345",
+					Name:     "Amsterdam",
+					Sponsor:  "Example",
+					Country:  "Netherlands",
+					Lat:      52.3667,
+					Lon:      4.9,
+					Distance: 5,
+					Host:     "speedtest.example.com:8080",
+					URL:      "http://speedtest.example.com:8080/speedtest/upload.php",
+				},
+			},
+		},
 		"error-from-doer-fail": {
 			setup: func() *mocks.HTTPDoer {
 				mockDoer := new(mocks.HTTPDoer)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1"
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
 				})).Return(nil, errors.New("random error"))
 
 				return mockDoer
 			},
-			expectedErr:      errors.New("failed to send request: random error"),
+			expectedErr:      errors.New("server discovery failed on https://www.speedtest.net/api/js/servers?engine=js: failed to send request: random error"),
 			expectedResponse: nil,
 		},
 	}
@@ -85,3 +118,225 @@ func TestGetServersDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectServers(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	tableTests := map[string]struct {
+		setup           func() *mocks.HTTPDoer
+		serverCount     int
+		expectedErr     error
+		expectedServers []string
+	}{
+		"success-lowest-latency": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{URL: "https://far.com/upload.php"},
+					{URL: "https://close.com/upload.php"},
+					{URL: "https://down.com/upload.php"},
+					{URL: "https://near.com/upload.php"},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=12"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					switch {
+					case strings.Contains(url, "far"):
+						return 100 * time.Millisecond, nil
+					case strings.Contains(url, "close"):
+						return 10 * time.Millisecond, nil
+					case strings.Contains(url, "near"):
+						return 20 * time.Millisecond, nil
+					}
+					return 0, errors.New("random error")
+				}
+
+				return mockDoer
+			},
+			serverCount: 2,
+			expectedErr: nil,
+			expectedServers: []string{
+				"https://close.com/upload.php",
+				"https://near.com/upload.php",
+			},
+		},
+		"success-fewer-candidates-than-servers-ranked": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{URL: "https://far.com/upload.php"},
+					{URL: "https://close.com/upload.php"},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=24"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					if strings.Contains(url, "far") {
+						return 100 * time.Millisecond, nil
+					}
+					return 10 * time.Millisecond, nil
+				}
+
+				return mockDoer
+			},
+			serverCount: 12,
+			expectedErr: nil,
+			expectedServers: []string{
+				"https://close.com/upload.php",
+				"https://far.com/upload.php",
+			},
+		},
+		"error-from-all-pings-fail": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{URL: "https://far.com/upload.php"},
+					{URL: "https://close.com/upload.php"},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					return 0, errors.New("random error")
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			expectedErr: errors.New("no servers available: failed to reach any of candidate servers"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(
+				&config.Config{
+					ServerCount: testCase.serverCount,
+				},
+				doer,
+			)
+
+			servers, err := cli.selectServers(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+
+				urls := make([]string, 0, len(servers))
+				for _, server := range servers {
+					urls = append(urls, server.URL)
+				}
+				assert.Equal(t, testCase.expectedServers, urls)
+			}
+		})
+	}
+}
+
+func TestSelectServersCached(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	buf := &bytes.Buffer{}
+	servers := []serverDetails{
+		{URL: "https://example.com/upload.php"},
+	}
+	err := json.NewEncoder(buf).Encode(&servers)
+	assert.NoError(t, err)
+
+	mockDoer := mocks.NewHTTPDoer(t)
+	mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
+	})).Return(&http.Response{
+		Body: io.NopCloser(buf),
+	}, nil).Once()
+
+	pings := 0
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		pings++
+		return time.Millisecond, nil
+	}
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount: 1,
+		},
+		mockDoer,
+	)
+
+	first, err := cli.selectServers(context.Background())
+	assert.NoError(t, err)
+
+	second, err := cli.Servers(context.Background())
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 1)
+	assert.Equal(t, first[0].server(), second[0])
+	assert.Equal(t, candidatePingCount, pings)
+}
+
+func TestSelectServersResetAfterFailure(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+		defaultPingFunc = ping
+	})
+
+	// server list is requested again, since
+	// selected server failed download phase
+	mockDoer := mocks.NewHTTPDoer(t)
+	for i := 0; i < 2; i++ {
+		mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
+		})).Return(&http.Response{
+			Body: io.NopCloser(bytes.NewBufferString(`[{"url":"https://example.com/upload.php"}]`)),
+		}, nil).Once()
+	}
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
+		return 0, errors.New("random error")
+	}
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount: 1,
+		},
+		mockDoer,
+	)
+
+	_, err := cli.MeasureDownloadTransfer(context.Background())
+	assert.Error(t, err)
+
+	servers, err := cli.selectServers(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, servers, 1)
+}
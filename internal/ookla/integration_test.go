@@ -0,0 +1,49 @@
+package ookla
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSelfHostedServer measures against self-hosted server,
+// so client is tested against real HTTP endpoints
+func TestSelfHostedServer(t *testing.T) {
+	srv := httptest.NewServer(server.New(server.WithName("Local", "Test", "Nowhere")))
+	defer srv.Close()
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount:   1,
+			ServerListURL: srv.URL + "/api/js/servers",
+		},
+		&http.Client{Timeout: 10 * time.Second},
+	)
+
+	servers, err := cli.Servers(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, servers, 1) {
+		assert.Equal(t, "Local", servers[0].Name)
+		assert.Equal(t, srv.URL+"/upload.php", servers[0].URL)
+	}
+
+	latency, err := cli.MeasureLatency(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, latency.Avg > 0 && latency.Avg < time.Second, latency.Avg)
+
+	download, err := cli.MeasureDownloadTransfer(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(workload*downloadLength*downloadWidth*2), download.Bytes)
+	assert.True(t, download.Rate > 0, download.Rate)
+
+	upload, err := cli.MeasureUploadTransfer(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, upload.Bytes > workload*uploadSize, upload.Bytes)
+	assert.True(t, upload.Rate > 0, upload.Rate)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
@@ -16,11 +15,19 @@ import (
 const (
 	downloadServerURLSuffix = "/upload.php"
 
+	// downloadLength is a dimension of square image
+	// requested in iteration based download test
 	downloadLength = 1000
-	downloadWidth  = 1000
-	downloadSize   = float64(downloadLength) * downloadWidth * 2
+
+	// maxDownloadStreams limits amount of concurrent
+	// requests in duration based download test
+	maxDownloadStreams = 16
 )
 
+// downloadDimensions are dimensions of square images
+// served by speedtest.net servers, from smallest to largest
+var downloadDimensions = []int{350, 500, 750, 1000, 1500, 2000, 2500, 3000, 3500, 4000}
+
 // DefaultDownloadFunc is a variable to wrap download function
 // for deterministic results
 var defaultDownloadFunc = download
@@ -30,77 +37,158 @@ func (c *Client) MeasureDownload(ctx context.Context) (
 	downloadRate measurement.BitRate,
 	err error,
 ) {
-	servers, err := c.getServersDetails(ctx)
+	transfer, err := c.MeasureDownloadTransfer(ctx)
 	if err != nil {
 		return 0, err
 	}
 
+	return transfer.Rate, nil
+}
+
+// MeasureDownloadTransfer measures download speed per second using Ookla's speedtest.net API
+// and returns details of measurement including per server results, failed servers
+// are handled according to failure policy, in case of error results measured
+// so far are returned as well
+func (c *Client) MeasureDownloadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.selectServers(ctx)
+	if err != nil {
+		return measurement.Transfer{}, err
+	}
+
 	// for each server calculate download speeds
-	// and take average number
-	avgDownloadRate := 0.0
+	// and aggregate rates of succeeded ones
+	start := time.Now()
+	rates := make([]measurement.BitRate, 0, len(servers))
+	failures := 0
 	for _, server := range servers {
 		url := strings.TrimSuffix(server.URL, downloadServerURLSuffix)
 
-		downloadRate, err := c.measureDownload(ctx, url)
+		serverStart := time.Now()
+		meter := c.conf.NewMeter()
+		err := c.measureDownload(ctx, url, meter)
+		serverTransfer := measurement.ServerTransfer{
+			Server:   server.server(),
+			Duration: time.Since(serverStart),
+			Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseDownload, url, err),
+		}
 		if err != nil {
-			return 0, err
+			transfer.Servers = append(transfer.Servers, serverTransfer)
+			failures++
+			c.resetServers()
+			if !c.conf.Tolerates(failures, len(servers)) {
+				transfer.Duration = time.Since(start)
+				return transfer, transfer.Err()
+			}
+			continue
 		}
 
-		avgDownloadRate += downloadRate
+		serverTransfer.Rate = meter.SampledRate()
+		serverTransfer.Bytes = meter.Bytes()
+		serverTransfer.Spread = meter.SampleSpread()
+		transfer.Servers = append(transfer.Servers, serverTransfer)
+
+		rates = append(rates, serverTransfer.Rate)
+		transfer.Bytes += serverTransfer.Bytes
 	}
-	avgDownloadRate = avgDownloadRate / float64(len(servers))
 
-	return measurement.BitRate(avgDownloadRate), nil
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers(rates)
+	transfer.Duration = time.Since(start)
+
+	return transfer, nil
 }
 
-// measureDownload measures download speed by requesting provided url,
-// it sends n iterations of requests to server
-// TODO: change n iterations to be based on latency, coordinates of server/user
-// and initial warm up
-func (c *Client) measureDownload(ctx context.Context, url string) (float64, error) {
+// measureDownload measures download speed by requesting provided url
+// and counting downloaded bytes in meter, if test duration is set,
+// then download test is time-boxed, otherwise it sends n iterations
+// of requests to server
+func (c *Client) measureDownload(ctx context.Context, url string, meter *measurement.Meter) error {
+	if c.conf.TestDuration > 0 {
+		return c.measureDownloadAdaptive(ctx, url, meter)
+	}
+
 	eg := errgroup.Group{}
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseDownload, url)
 
-	start := time.Now()
 	for i := 0; i < workload; i++ {
 		eg.Go(func() error {
-			return defaultDownloadFunc(ctx, c.doer, url)
+			_, err := defaultDownloadFunc(ctx, c.doer, url, downloadLength, meter)
+			return err
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		return 0, err
-	}
-	end := time.Now()
+	err := eg.Wait()
+	stopProgress()
+
+	return err
+}
 
-	return downloadSize * bitsInByte * workload / end.Sub(start).Seconds(), nil
+// measureDownloadAdaptive measures download speed by requesting provided url
+// in concurrent streams until test duration is over, requests that finish
+// too fast to saturate the link increase image size, and when the largest
+// image is reached, amount of streams
+func (c *Client) measureDownloadAdaptive(ctx context.Context, url string, meter *measurement.Meter) error {
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseDownload, url)
+	defer stopProgress()
+
+	return measurement.Ramp(
+		ctx,
+		c.conf.TestDuration,
+		len(downloadDimensions),
+		workload,
+		maxDownloadStreams,
+		func(ctx context.Context, sizeIdx int) error {
+			_, err := defaultDownloadFunc(ctx, c.doer, url, downloadDimensions[sizeIdx], meter)
+			return err
+		},
+	)
 }
 
-// download downloads random content from provided url
-func download(ctx context.Context, doer HTTPDoer, url string) error {
+// download downloads random square image with provided dimension
+// from provided url to meter and returns amount of received bytes,
+// failed or truncated body read is returned as error
+func download(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	dimension int,
+	meter *measurement.Meter,
+) (int64, error) {
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodGet,
-		fmt.Sprintf("%s/random%dx%d.jpg", url, downloadLength, downloadWidth),
+		fmt.Sprintf("%s/random%dx%d.jpg", url, dimension, dimension),
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := doer.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return 0, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			fmt.Printf("failed to close response body: %v\n", err)
 		}
 	}()
-	_, err = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return 0, measurement.StatusError(resp.StatusCode)
+	}
+
+	n, err := io.Copy(meter, resp.Body)
 	if err != nil {
-		// just log this error
-		// as it's not very important
-		fmt.Printf("failed to copy response body: %v\n", err)
+		return n, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	if resp.ContentLength > 0 && n < resp.ContentLength {
+		return n, fmt.Errorf("failed to copy response body: %w", io.ErrUnexpectedEOF)
 	}
 
-	return nil
+	return n, nil
 }
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/bejaneps/speedtest/internal/config"
@@ -17,11 +19,21 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+const (
+	downloadWidth = 1000
+	downloadSize  = float64(downloadLength) * downloadWidth * 2
+)
+
 func TestMeasureDownload(t *testing.T) {
 	t.Cleanup(func() {
 		defaultDownloadFunc = download
+		defaultPingFunc = ping
 	})
 
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
 	tableTests := map[string]struct {
 		setup             func() *mocks.HTTPDoer
 		serverCount       int
@@ -41,15 +53,16 @@ func TestMeasureDownload(t *testing.T) {
 
 				mockDoer := mocks.NewHTTPDoer(t)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1" ||
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11" ||
 						req.URL.String() == "https://example.com/random1000x1000.jpg"
 				})).Return(&http.Response{
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string) error {
+				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
 					time.Sleep(time.Second / 10)
-					return nil
+					meter.Add(int64(downloadSize))
+					return int64(downloadSize), nil
 				}
 
 				return mockDoer
@@ -71,15 +84,16 @@ func TestMeasureDownload(t *testing.T) {
 
 				mockDoer := mocks.NewHTTPDoer(t)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1" ||
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11" ||
 						req.URL.String() == "https://example.com/random1000x1000.jpg"
 				})).Return(&http.Response{
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string) error {
+				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
 					time.Sleep(1 * time.Second)
-					return nil
+					meter.Add(int64(downloadSize))
+					return int64(downloadSize), nil
 				}
 
 				return mockDoer
@@ -101,20 +115,20 @@ func TestMeasureDownload(t *testing.T) {
 
 				mockDoer := mocks.NewHTTPDoer(t)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1" ||
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11" ||
 						req.URL.String() == "https://example.com/random1000x1000.jpg"
 				})).Return(&http.Response{
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string) error {
-					return errors.New("random error")
+				defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
+					return 0, errors.New("random error")
 				}
 
 				return mockDoer
 			},
 			serverCount:       1,
-			expectedErr:       errors.New("random error"),
+			expectedErr:       errors.New("download: transfer failed on https://example.com: random error"),
 			expectedRateRange: []measurement.BitRate{0, 0},
 		},
 	}
@@ -146,8 +160,9 @@ func TestMeasureDownload(t *testing.T) {
 
 func TestDownload(t *testing.T) {
 	tableTests := map[string]struct {
-		setup       func() *mocks.HTTPDoer
-		expectedErr error
+		setup         func() *mocks.HTTPDoer
+		expectedErr   error
+		expectedBytes int64
 	}{
 		"success": {
 			setup: func() *mocks.HTTPDoer {
@@ -155,12 +170,46 @@ func TestDownload(t *testing.T) {
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 					return req.URL.String() == "https://example.com/random1000x1000.jpg"
 				})).Return(&http.Response{
-					Body: io.NopCloser(bytes.NewBufferString("blob")),
+					ContentLength: 4,
+					Body:          io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   nil,
+			expectedBytes: 4,
+		},
+		"error-from-truncated-body": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/random1000x1000.jpg"
+				})).Return(&http.Response{
+					ContentLength: 10,
+					Body:          io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("failed to copy response body: unexpected EOF"),
+			expectedBytes: 4,
+		},
+		"error-from-body-read-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/random1000x1000.jpg"
+				})).Return(&http.Response{
+					Body: io.NopCloser(io.MultiReader(
+						bytes.NewBufferString("blob"),
+						iotest.ErrReader(errors.New("random error")),
+					)),
 				}, nil)
 
 				return mockDoer
 			},
-			expectedErr: nil,
+			expectedErr:   errors.New("failed to copy response body: random error"),
+			expectedBytes: 4,
 		},
 		"error-from-doer-fail": {
 			setup: func() *mocks.HTTPDoer {
@@ -171,7 +220,23 @@ func TestDownload(t *testing.T) {
 
 				return mockDoer
 			},
-			expectedErr: errors.New("failed to send request: random error"),
+			expectedErr:   errors.New("failed to send request: random error"),
+			expectedBytes: 0,
+		},
+		"error-from-status-code": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/random1000x1000.jpg"
+				})).Return(&http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Body:       io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr:   errors.New("unexpected status code 503"),
+			expectedBytes: 0,
 		},
 	}
 
@@ -180,12 +245,252 @@ func TestDownload(t *testing.T) {
 		t.Run(testName, func(t *testing.T) {
 			doer := testCase.setup()
 
-			err := download(context.Background(), doer, "https://example.com")
+			n, err := download(context.Background(), doer, "https://example.com", 1000, measurement.NewMeter(0, 0, 0, nil))
+			assert.Equal(t, testCase.expectedBytes, n)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMeasureDownloadTransfer(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+		defaultPingFunc = ping
+	})
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
+	buf := &bytes.Buffer{}
+	servers := []serverDetails{
+		{
+			ID:  "1",
+			URL: "https://example.com/upload.php",
+		},
+	}
+	err := json.NewEncoder(buf).Encode(&servers)
+	assert.NoError(t, err)
+
+	mockDoer := mocks.NewHTTPDoer(t)
+	mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
+	})).Return(&http.Response{
+		Body: io.NopCloser(buf),
+	}, nil)
+
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
+		time.Sleep(time.Second / 10)
+		meter.Add(int64(downloadSize))
+		return int64(downloadSize), nil
+	}
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount: 1,
+		},
+		mockDoer,
+	)
+
+	transfer, err := cli.MeasureDownloadTransfer(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(downloadSize*workload), transfer.Bytes)
+	assert.Len(t, transfer.Servers, 1)
+
+	server := transfer.Servers[0]
+	assert.Equal(t, "1", server.Server.ID)
+	assert.Equal(t, transfer.Rate, server.Rate)
+	assert.Equal(t, transfer.Bytes, server.Bytes)
+	assert.True(t, server.Duration >= time.Second/10, server.Duration)
+	assert.NoError(t, server.Err)
+}
+
+func TestMeasureDownloadTransferFailurePolicy(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+		defaultPingFunc = ping
+	})
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
+	tableTests := map[string]struct {
+		conf             *config.Config
+		expectedErr      error
+		expectedFailures int
+	}{
+		"error-fail-fast": {
+			conf: &config.Config{
+				ServerCount:   2,
+				FailurePolicy: config.FailFast,
+			},
+			expectedErr:      errors.New("download: transfer failed on https://down.com: random error"),
+			expectedFailures: 1,
+		},
+		"success-tolerate-failures": {
+			conf: &config.Config{
+				ServerCount:   2,
+				FailurePolicy: config.TolerateFailures,
+				MaxFailures:   1,
+			},
+			expectedErr:      nil,
+			expectedFailures: 1,
+		},
+		"success-best-effort": {
+			conf: &config.Config{
+				ServerCount:   2,
+				FailurePolicy: config.BestEffort,
+			},
+			expectedErr:      nil,
+			expectedFailures: 1,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			servers := []serverDetails{
+				{
+					URL: "https://down.com/upload.php",
+				},
+				{
+					URL: "https://example.com/upload.php",
+				},
+			}
+			err := json.NewEncoder(buf).Encode(&servers)
+			assert.NoError(t, err)
+
+			mockDoer := mocks.NewHTTPDoer(t)
+			mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+				return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=12"
+			})).Return(&http.Response{
+				Body: io.NopCloser(buf),
+			}, nil)
+
+			defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
+				if url == "https://down.com" {
+					return 0, errors.New("random error")
+				}
+				time.Sleep(time.Second / 10)
+				meter.Add(int64(downloadSize))
+				return int64(downloadSize), nil
+			}
+
+			cli := NewClient(testCase.conf, mockDoer)
+
+			transfer, err := cli.MeasureDownloadTransfer(context.Background())
+			assert.Len(t, transfer.Errors(), testCase.expectedFailures)
 			if testCase.expectedErr != nil {
 				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
+				assert.True(t, transfer.Rate > 600000000 && transfer.Rate < 690000000, transfer.Rate)
 			}
 		})
 	}
 }
+
+func TestMeasureDownloadAdaptive(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+		defaultPingFunc = ping
+	})
+
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
+	mu := sync.Mutex{}
+	dimensions := map[int]int{}
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
+		mu.Lock()
+		dimensions[dimension]++
+		mu.Unlock()
+
+		time.Sleep(time.Second / 100)
+		meter.Add(int64(dimension * dimension * 2))
+		return int64(dimension * dimension * 2), nil
+	}
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount:  1,
+			TestDuration: time.Second,
+		},
+		mocks.NewHTTPDoer(t),
+	)
+
+	start := time.Now()
+	meter := cli.conf.NewMeter()
+	err := cli.measureDownload(context.Background(), "https://example.com", meter)
+	elapsed := time.Since(start)
+	assert.NoError(t, err)
+
+	rate, size := float64(meter.SampledRate()), meter.Bytes()
+
+	// test fills target duration without overshooting it much
+	assert.True(t, elapsed >= time.Second && elapsed < 2*time.Second, elapsed)
+
+	// image size is ramped up to the largest one
+	assert.Equal(t, workload, dimensions[350])
+	assert.True(t, dimensions[4000] > workload, dimensions[4000])
+
+	assert.True(t, size > 4000*4000*2, size)
+	assert.InDelta(t, float64(size)*bitsInByte/elapsed.Seconds(), rate, rate*0.05)
+}
+
+func TestMeasureDownloadAdaptiveSlowServer(t *testing.T) {
+	t.Cleanup(func() {
+		defaultDownloadFunc = download
+	})
+
+	// every request takes longer than the rest of
+	// test duration, so it's interrupted midway
+	defaultDownloadFunc = func(ctx context.Context, doer HTTPDoer, url string, dimension int, meter *measurement.Meter) (int64, error) {
+		var n int64
+		ticker := time.NewTicker(time.Second / 100)
+		defer ticker.Stop()
+
+		for i := 0; i < 70; i++ {
+			select {
+			case <-ctx.Done():
+				return n, ctx.Err()
+			case <-ticker.C:
+				meter.Add(1000)
+				n += 1000
+			}
+		}
+
+		return n, nil
+	}
+
+	cli := NewClient(
+		&config.Config{
+			ServerCount:  1,
+			TestDuration: time.Second,
+		},
+		mocks.NewHTTPDoer(t),
+	)
+
+	start := time.Now()
+	meter := cli.conf.NewMeter()
+	err := cli.measureDownload(context.Background(), "https://example.com", meter)
+	elapsed := time.Since(start)
+	assert.NoError(t, err)
+
+	// deadline ends test while second requests of streams
+	// are in flight instead of waiting for them to finish
+	assert.True(t, elapsed >= time.Second && elapsed < 1100*time.Millisecond, elapsed)
+
+	// partially received bytes are counted, streams
+	// are never idle, so rate isn't lowered
+	assert.True(t, meter.Bytes() > workload*70*1000, meter.Bytes())
+	rate := float64(meter.SampledRate())
+	assert.InDelta(t, float64(workload*1000*100*bitsInByte), rate, rate*0.2)
+}
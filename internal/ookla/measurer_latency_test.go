@@ -0,0 +1,175 @@
+package ookla
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/ookla/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureLatency(t *testing.T) {
+	t.Cleanup(func() {
+		defaultPingFunc = ping
+	})
+
+	tableTests := map[string]struct {
+		setup           func() *mocks.HTTPDoer
+		serverCount     int
+		expectedErr     error
+		expectedLatency measurement.Latency
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{
+						URL: "https://example.com/upload.php",
+					},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				rtts := []time.Duration{10, 20}
+				i := 0
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					rtt := rtts[i%len(rtts)] * time.Millisecond
+					i++
+					return rtt, nil
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			expectedErr: nil,
+			expectedLatency: measurement.Latency{
+				Min:    10 * time.Millisecond,
+				Avg:    15 * time.Millisecond,
+				Max:    20 * time.Millisecond,
+				Jitter: 10 * time.Millisecond,
+			},
+		},
+		"error-from-ping-fail": {
+			setup: func() *mocks.HTTPDoer {
+				buf := &bytes.Buffer{}
+				servers := []serverDetails{
+					{
+						URL: "https://example.com/upload.php",
+					},
+				}
+				err := json.NewEncoder(buf).Encode(&servers)
+				assert.NoError(t, err)
+
+				mockDoer := mocks.NewHTTPDoer(t)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
+				})).Return(&http.Response{
+					Body: io.NopCloser(buf),
+				}, nil)
+
+				// pings made while selecting
+				// servers succeed
+				i := 0
+				defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+					i++
+					if i <= candidatePingCount {
+						return time.Millisecond, nil
+					}
+					return 0, errors.New("random error")
+				}
+
+				return mockDoer
+			},
+			serverCount: 1,
+			expectedErr: errors.New("latency: transfer failed on https://example.com: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			cli := NewClient(
+				&config.Config{
+					ServerCount: testCase.serverCount,
+				},
+				doer,
+			)
+
+			latency, err := cli.MeasureLatency(context.Background())
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedLatency, latency)
+			}
+		})
+	}
+}
+
+func TestPing(t *testing.T) {
+	tableTests := map[string]struct {
+		setup       func() *mocks.HTTPDoer
+		expectedErr error
+	}{
+		"success": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.
+					On("Do", mock.MatchedBy(func(req *http.Request) bool {
+						return req.URL.String() == "https://example.com/latency.txt"
+					})).
+					WaitUntil(time.After(time.Second/10)).
+					Return(&http.Response{
+						Body: io.NopCloser(bytes.NewBufferString("test=test")),
+					}, nil)
+
+				return mockDoer
+			},
+			expectedErr: nil,
+		},
+		"error-from-doer-fail": {
+			setup: func() *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					return req.URL.String() == "https://example.com/latency.txt"
+				})).Return(nil, errors.New("random error"))
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request: random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			doer := testCase.setup()
+
+			rtt, err := ping(context.Background(), doer, "https://example.com")
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, rtt >= time.Second/20 && rtt < time.Second/5, rtt)
+			}
+		})
+	}
+}
@@ -1,13 +1,13 @@
 package ookla
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	stdURL "net/url"
-	"strings"
 	"time"
 
 	"github.com/bejaneps/speedtest/internal/measurement"
@@ -26,80 +26,169 @@ func (c *Client) MeasureUpload(ctx context.Context) (
 	uploadRate measurement.BitRate,
 	err error,
 ) {
-	servers, err := c.getServersDetails(ctx)
+	transfer, err := c.MeasureUploadTransfer(ctx)
 	if err != nil {
 		return 0, err
 	}
 
+	return transfer.Rate, nil
+}
+
+// MeasureUploadTransfer measures upload speed per second using Ookla's speedtest.net API
+// and returns details of measurement including per server results, failed servers
+// are handled according to failure policy, in case of error results measured
+// so far are returned as well
+func (c *Client) MeasureUploadTransfer(ctx context.Context) (
+	transfer measurement.Transfer,
+	err error,
+) {
+	ctx, cancel := c.conf.WithTimeout(ctx)
+	defer cancel()
+
+	servers, err := c.selectServers(ctx)
+	if err != nil {
+		return measurement.Transfer{}, err
+	}
+
+	// payload is generated once before any meter
+	// is started, so its generation isn't measured
+	content := uploadContent()
+
 	// for each server calculate upload speeds
-	// and take average number
-	avgUploadRate := 0.0
+	// and aggregate rates of succeeded ones
+	start := time.Now()
+	rates := make([]measurement.BitRate, 0, len(servers))
+	failures := 0
 	for _, server := range servers {
 		url := server.URL
 
-		downloadRate, err := c.measureUpload(ctx, url)
+		serverStart := time.Now()
+		meter := c.conf.NewMeter()
+		err := c.measureUpload(ctx, url, content, meter)
+		serverTransfer := measurement.ServerTransfer{
+			Server:   server.server(),
+			Duration: time.Since(serverStart),
+			Err:      measurement.NewError(measurement.ErrTransfer, measurement.PhaseUpload, url, err),
+		}
 		if err != nil {
-			return 0, err
+			transfer.Servers = append(transfer.Servers, serverTransfer)
+			failures++
+			c.resetServers()
+			if !c.conf.Tolerates(failures, len(servers)) {
+				transfer.Duration = time.Since(start)
+				return transfer, transfer.Err()
+			}
+			continue
 		}
 
-		avgUploadRate += downloadRate
+		serverTransfer.Rate = meter.SampledRate()
+		serverTransfer.Bytes = meter.Bytes()
+		serverTransfer.Spread = meter.SampleSpread()
+		transfer.Servers = append(transfer.Servers, serverTransfer)
+
+		rates = append(rates, serverTransfer.Rate)
+		transfer.Bytes += serverTransfer.Bytes
 	}
-	avgUploadRate = avgUploadRate / float64(len(servers))
 
-	return measurement.BitRate(avgUploadRate), nil
+	transfer.Rate, transfer.Spread = c.conf.AggregateServers(rates)
+	transfer.Duration = time.Since(start)
+
+	return transfer, nil
 }
 
-func (c *Client) measureUpload(ctx context.Context, url string) (float64, error) {
+// measureUpload measures upload speed by posting content to provided url
+// and counting uploaded bytes in meter, it sends n iterations of
+// requests to server
+func (c *Client) measureUpload(
+	ctx context.Context,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) error {
 	eg := errgroup.Group{}
+	stopProgress := c.conf.WatchProgress(meter, measurement.PhaseUpload, url)
 
-	start := time.Now()
 	for i := 0; i < workload; i++ {
 		eg.Go(func() error {
-			return defaultUploadFunc(ctx, c.doer, url)
+			_, err := defaultUploadFunc(ctx, c.doer, url, content, meter)
+			return err
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		return 0, err
-	}
-	end := time.Now()
+	err := eg.Wait()
+	stopProgress()
 
-	return uploadSize * bitsInByte * workload / end.Sub(start).Seconds(), nil
+	return err
 }
 
-// upload uploads random content to provided url
-func upload(ctx context.Context, doer HTTPDoer, url string) error {
+// uploadContent returns form with random content to be posted by upload
+func uploadContent() []byte {
 	values := stdURL.Values{}
-	randString := random.String(uploadSize)
+	values.Add("content", random.String(uploadSize))
 
-	values.Add("content", randString)
+	return []byte(values.Encode())
+}
+
+// upload uploads provided form content to provided url, counting sent
+// bytes in meter, and returns amount of sent bytes, failed or truncated
+// request body is returned as error, content is only read, so it can
+// be shared by concurrent uploads
+func upload(
+	ctx context.Context,
+	doer HTTPDoer,
+	url string,
+	content []byte,
+	meter *measurement.Meter,
+) (int64, error) {
+	body := &countingReader{r: meter.Reader(bytes.NewReader(content))}
 
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		url,
-		strings.NewReader(values.Encode()),
+		body,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	req.ContentLength = int64(len(content))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := doer.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return body.n, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			fmt.Printf("failed to close response body: %v\n", err)
 		}
 	}()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return body.n, measurement.StatusError(resp.StatusCode)
+	}
+
 	_, err = io.Copy(ioutil.Discard, resp.Body)
+	meter.Stop()
 	if err != nil {
-		// just log this error
-		// as it's not very important
-		fmt.Printf("failed to copy response body: %v\n", err)
+		return body.n, fmt.Errorf("failed to copy response body: %w", err)
+	}
+	if body.n < req.ContentLength {
+		return body.n, fmt.Errorf("failed to send request body: %w", io.ErrUnexpectedEOF)
 	}
 
-	return nil
+	return body.n, nil
+}
+
+// countingReader counts amount of bytes read from underlying reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read reads from underlying reader and counts read bytes
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
 }
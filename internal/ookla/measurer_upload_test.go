@@ -21,8 +21,13 @@ import (
 func TestMeasureUpload(t *testing.T) {
 	t.Cleanup(func() {
 		defaultUploadFunc = upload
+		defaultPingFunc = ping
 	})
 
+	defaultPingFunc = func(ctx context.Context, doer HTTPDoer, url string) (time.Duration, error) {
+		return time.Millisecond, nil
+	}
+
 	tableTests := map[string]struct {
 		setup             func() *mocks.HTTPDoer
 		serverCount       int
@@ -42,15 +47,16 @@ func TestMeasureUpload(t *testing.T) {
 
 				mockDoer := mocks.NewHTTPDoer(t)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1" ||
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11" ||
 						req.URL.String() == "https://example.com/upload.php"
 				})).Return(&http.Response{
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string) error {
+				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
 					time.Sleep(time.Second / 10)
-					return nil
+					meter.Add(uploadSize)
+					return uploadSize, nil
 				}
 
 				return mockDoer
@@ -72,15 +78,16 @@ func TestMeasureUpload(t *testing.T) {
 
 				mockDoer := mocks.NewHTTPDoer(t)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1" ||
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11" ||
 						req.URL.String() == "https://example.com/upload.php"
 				})).Return(&http.Response{
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string) error {
+				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
 					time.Sleep(1 * time.Second)
-					return nil
+					meter.Add(uploadSize)
+					return uploadSize, nil
 				}
 
 				return mockDoer
@@ -93,13 +100,13 @@ func TestMeasureUpload(t *testing.T) {
 			setup: func() *mocks.HTTPDoer {
 				mockDoer := mocks.NewHTTPDoer(t)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1"
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11"
 				})).Return(nil, errors.New("random error"))
 
 				return mockDoer
 			},
 			serverCount:       1,
-			expectedErr:       errors.New("failed to send request: random error"),
+			expectedErr:       errors.New("server discovery failed on https://www.speedtest.net/api/js/servers?engine=js: failed to send request: random error"),
 			expectedRateRange: []measurement.BitRate{0, 0},
 		},
 		"error-from-upload-fail": {
@@ -115,20 +122,20 @@ func TestMeasureUpload(t *testing.T) {
 
 				mockDoer := mocks.NewHTTPDoer(t)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
-					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=1" ||
+					return req.URL.String() == "https://www.speedtest.net/api/js/servers?engine=js&limit=11" ||
 						req.URL.String() == "https://example.com/upload.php"
 				})).Return(&http.Response{
 					Body: io.NopCloser(buf),
 				}, nil)
 
-				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string) error {
-					return errors.New("random error")
+				defaultUploadFunc = func(ctx context.Context, doer HTTPDoer, url string, content []byte, meter *measurement.Meter) (int64, error) {
+					return 0, errors.New("random error")
 				}
 
 				return mockDoer
 			},
 			serverCount:       1,
-			expectedErr:       errors.New("random error"),
+			expectedErr:       errors.New("upload: transfer failed on https://example.com/upload.php: random error"),
 			expectedRateRange: []measurement.BitRate{0, 0},
 		},
 	}
@@ -161,11 +168,11 @@ func TestMeasureUpload(t *testing.T) {
 
 func TestUpload(t *testing.T) {
 	tableTests := map[string]struct {
-		setup       func() *mocks.HTTPDoer
+		setup       func(sent *int64) *mocks.HTTPDoer
 		expectedErr error
 	}{
 		"success": {
-			setup: func() *mocks.HTTPDoer {
+			setup: func(sent *int64) *mocks.HTTPDoer {
 				mockDoer := new(mocks.HTTPDoer)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 					b, err := io.ReadAll(req.Body)
@@ -175,6 +182,7 @@ func TestUpload(t *testing.T) {
 					if !strings.HasPrefix(string(b), "content=") {
 						return false
 					}
+					*sent = int64(len(b))
 					return req.URL.String() == "https://example.com/upload.php"
 				})).Return(&http.Response{
 					Body: io.NopCloser(bytes.NewBufferString("blob")),
@@ -184,8 +192,27 @@ func TestUpload(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		"error-from-truncated-body": {
+			setup: func(sent *int64) *mocks.HTTPDoer {
+				mockDoer := new(mocks.HTTPDoer)
+				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+					b := make([]byte, 100)
+					n, err := req.Body.Read(b)
+					if err != nil {
+						return false
+					}
+					*sent = int64(n)
+					return req.URL.String() == "https://example.com/upload.php"
+				})).Return(&http.Response{
+					Body: io.NopCloser(bytes.NewBufferString("blob")),
+				}, nil)
+
+				return mockDoer
+			},
+			expectedErr: errors.New("failed to send request body: unexpected EOF"),
+		},
 		"error-from-doer-fail": {
-			setup: func() *mocks.HTTPDoer {
+			setup: func(sent *int64) *mocks.HTTPDoer {
 				mockDoer := new(mocks.HTTPDoer)
 				mockDoer.On("Do", mock.MatchedBy(func(req *http.Request) bool {
 					b, err := io.ReadAll(req.Body)
@@ -195,6 +222,7 @@ func TestUpload(t *testing.T) {
 					if !strings.HasPrefix(string(b), "content=") {
 						return false
 					}
+					*sent = int64(len(b))
 					return req.URL.String() == "https://example.com/upload.php"
 				})).Return(nil, errors.New("random error"))
 
@@ -207,13 +235,16 @@ func TestUpload(t *testing.T) {
 	for testName, testCase := range tableTests {
 		testCase := testCase
 		t.Run(testName, func(t *testing.T) {
-			doer := testCase.setup()
+			var sent int64
+			doer := testCase.setup(&sent)
 
-			err := upload(context.Background(), doer, "https://example.com/upload.php")
+			n, err := upload(context.Background(), doer, "https://example.com/upload.php", uploadContent(), measurement.NewMeter(0, 0, 0, nil))
+			assert.Equal(t, sent, n)
 			if testCase.expectedErr != nil {
 				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
+				assert.True(t, n >= uploadSize, n)
 			}
 		})
 	}
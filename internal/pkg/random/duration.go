@@ -0,0 +1,27 @@
+package random
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// durationMu guards durationSource, since
+	// it isn't safe for concurrent use
+	durationMu     sync.Mutex
+	durationSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Duration generates random duration in range [0, max),
+// zero is returned if max isn't positive
+func Duration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	durationMu.Lock()
+	defer durationMu.Unlock()
+
+	return time.Duration(durationSource.Int63n(int64(max)))
+}
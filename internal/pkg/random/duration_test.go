@@ -0,0 +1,20 @@
+package random_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuration(t *testing.T) {
+	max := time.Second
+
+	for i := 0; i < 100; i++ {
+		d := random.Duration(max)
+		assert.True(t, d >= 0 && d < max, d)
+	}
+
+	assert.Equal(t, time.Duration(0), random.Duration(0))
+}
@@ -2,16 +2,24 @@ package random
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
 const charset = "aBcD123.!#"
 
-var randSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+var (
+	// randMu guards randSource, since it isn't safe for concurrent use
+	randMu     sync.Mutex
+	randSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
 
 // StringWithCharset generates random string of provided length
 // using provided charset
 func StringWithCharset(length int, charset string) string {
+	randMu.Lock()
+	defer randMu.Unlock()
+
 	b := make([]byte, length)
 	for i := range b {
 		b[i] = charset[randSource.Intn(len(charset))]
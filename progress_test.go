@@ -0,0 +1,81 @@
+package speedtest_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// progressTool is a tool registered by caller
+// outside of module, which reports progress
+const progressTool speedtest.Tool = "progress"
+
+var registerProgressTool sync.Once
+
+// progressMeasurer reports single progress of download
+type progressMeasurer struct {
+	conf *speedtest.Config
+}
+
+func (m *progressMeasurer) MeasureDownload(ctx context.Context) (speedtest.BitRate, error) {
+	m.conf.Progress(speedtest.Progress{
+		Phase:  speedtest.PhaseDownload,
+		Server: "https://example.com",
+		Rate:   speedtest.Mbps(100),
+	})
+
+	return speedtest.Mbps(100), nil
+}
+
+func (m *progressMeasurer) MeasureUpload(ctx context.Context) (speedtest.BitRate, error) {
+	return speedtest.Mbps(10), nil
+}
+
+func (m *progressMeasurer) MeasureLatency(ctx context.Context) (speedtest.Latency, error) {
+	return speedtest.Latency{}, nil
+}
+
+func TestProgressOptions(t *testing.T) {
+	registerProgressTool.Do(func() {
+		speedtest.Register(progressTool, func(conf *speedtest.Config, doer speedtest.HTTPDoer) (speedtest.Measurer, error) {
+			return &progressMeasurer{conf: conf}, nil
+		})
+	})
+
+	phases := []speedtest.Phase{speedtest.PhaseDownload}
+
+	var reports []speedtest.Progress
+	_, err := speedtest.Run(
+		context.Background(),
+		progressTool,
+		speedtest.WithPhases(phases...),
+		speedtest.WithProgress(func(p speedtest.Progress) {
+			reports = append(reports, p)
+		}),
+	)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, speedtest.PhaseDownload, reports[0].Phase)
+		assert.Equal(t, speedtest.Mbps(100), reports[0].Rate)
+	}
+
+	ch := make(chan speedtest.Progress, 1)
+	_, err = speedtest.Run(
+		context.Background(),
+		progressTool,
+		speedtest.WithPhases(phases...),
+		speedtest.WithProgressChan(ch),
+	)
+	assert.NoError(t, err)
+
+	select {
+	case p := <-ch:
+		assert.Equal(t, "https://example.com", p.Server)
+	case <-time.After(time.Second):
+		t.Fatal("progress wasn't received")
+	}
+}
@@ -0,0 +1,122 @@
+package speedtest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/bejaneps/speedtest/internal/cloudflare"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/librespeed"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/netflix"
+	"github.com/bejaneps/speedtest/internal/ookla"
+)
+
+// Config is a configuration of measurement built from options,
+// it's passed to factory of measurer
+type Config = config.Config
+
+// BitRate is a transfer rate in bits per second
+type BitRate = measurement.BitRate
+
+// Latency is a statistics of round trip times
+type Latency = measurement.Latency
+
+// Transfer is a result of download or upload measurement,
+// it's returned by TransferMeasurer
+type Transfer = measurement.Transfer
+
+// ServerTransfer is a result of transfer against single server
+type ServerTransfer = measurement.ServerTransfer
+
+// Server is a server used for measurement
+type Server = measurement.Server
+
+// Spread is a spread of rates aggregated into one
+type Spread = measurement.Spread
+
+// Aggregation combines multiple rates into a single one,
+// e.g. rates of servers into reported rate
+type Aggregation = measurement.Aggregation
+
+// Progress is an intermediate state of measurement
+// against single server reported by WithProgress
+type Progress = measurement.Progress
+
+// Phase is a phase of measurement run
+type Phase = measurement.Phase
+
+// Factory builds measurer of a tool from configuration and HTTP
+// client, which is built from transport options of configuration
+type Factory func(conf *Config, doer HTTPDoer) (Measurer, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[Tool]Factory{}
+)
+
+func init() {
+	Register(OoklaSpeedtest, func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		return ookla.NewClient(conf, doer), nil
+	})
+	Register(NetflixFast, func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		cli, err := netflix.NewClient(conf, doer)
+		if err != nil {
+			return nil, err
+		}
+
+		return cli, nil
+	})
+	Register(LibreSpeed, func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		return librespeed.NewClient(conf, doer), nil
+	})
+	Register(CloudflareSpeed, func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		return cloudflare.NewClient(conf, doer), nil
+	})
+}
+
+// Register makes measurement tool available in New and Run,
+// so third party tools can be plugged in. It panics if name of
+// tool is empty, factory is nil or tool is already registered
+func Register(tool Tool, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if tool == "" {
+		panic("speedtest: name of registered tool is empty")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("speedtest: factory of tool %q is nil", tool))
+	}
+	if _, ok := registry[tool]; ok {
+		panic(fmt.Sprintf("speedtest: tool %q is already registered", tool))
+	}
+
+	registry[tool] = factory
+}
+
+// Tools returns sorted names of registered tools
+func Tools() []Tool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	tools := make([]Tool, 0, len(registry))
+	for tool := range registry {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i] < tools[j]
+	})
+
+	return tools
+}
+
+// lookup returns factory of registered tool
+func lookup(tool Tool) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[tool]
+	return factory, ok
+}
@@ -0,0 +1,134 @@
+package speedtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMeasurer is a third party measurer
+// with fixed results
+type fakeMeasurer struct {
+	conf *Config
+	doer HTTPDoer
+}
+
+func (m *fakeMeasurer) MeasureDownload(ctx context.Context) (BitRate, error) {
+	return Mbps(100), nil
+}
+
+func (m *fakeMeasurer) MeasureUpload(ctx context.Context) (BitRate, error) {
+	return Mbps(10), nil
+}
+
+func (m *fakeMeasurer) MeasureLatency(ctx context.Context) (Latency, error) {
+	return Latency{Avg: 10 * time.Millisecond}, nil
+}
+
+func TestNew(t *testing.T) {
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "fake")
+		delete(registry, "broken")
+		registryMu.Unlock()
+	})
+
+	Register("fake", func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		return &fakeMeasurer{conf: conf, doer: doer}, nil
+	})
+	Register("broken", func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		return nil, errors.New("random error")
+	})
+
+	tableTests := map[string]struct {
+		tool        Tool
+		expectedErr error
+	}{
+		"success-built-in-tool": {
+			tool:        OoklaSpeedtest,
+			expectedErr: nil,
+		},
+		"success-registered-tool": {
+			tool:        "fake",
+			expectedErr: nil,
+		},
+		"error-from-unknown-tool": {
+			tool:        "unknown",
+			expectedErr: errors.New(`unknown measurement tool "unknown"`),
+		},
+		"error-from-factory-fail": {
+			tool:        "broken",
+			expectedErr: errors.New("random error"),
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			measurer, err := New(testCase.tool)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr.Error(), err.Error())
+				assert.Nil(t, measurer)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, measurer)
+			}
+		})
+	}
+}
+
+func TestRegisteredToolOptions(t *testing.T) {
+	t.Cleanup(func() {
+		registryMu.Lock()
+		delete(registry, "fake")
+		registryMu.Unlock()
+	})
+
+	Register("fake", func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		return &fakeMeasurer{conf: conf, doer: doer}, nil
+	})
+
+	client := &http.Client{}
+	measurer, err := New("fake", WithServerCount(3), WithHTTPClient(client))
+	assert.NoError(t, err)
+
+	fake := measurer.(*fakeMeasurer)
+	assert.Equal(t, 3, fake.conf.ServerCount)
+	assert.Equal(t, client, fake.doer)
+
+	result, err := Run(context.Background(), "fake")
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", result.Tool)
+	assert.Equal(t, Mbps(100), result.Download)
+}
+
+func TestRegister(t *testing.T) {
+	factory := func(conf *Config, doer HTTPDoer) (Measurer, error) {
+		return &fakeMeasurer{}, nil
+	}
+
+	assert.PanicsWithValue(t, `speedtest: tool "ookla" is already registered`, func() {
+		Register(OoklaSpeedtest, factory)
+	})
+	assert.PanicsWithValue(t, `speedtest: factory of tool "fake" is nil`, func() {
+		Register("fake", nil)
+	})
+	assert.PanicsWithValue(t, "speedtest: name of registered tool is empty", func() {
+		Register(Tool(""), factory)
+	})
+}
+
+func TestTools(t *testing.T) {
+	// tools registered by external tests
+	// may be listed along with built-in ones
+	tools := Tools()
+	assert.Subset(t, tools, []Tool{CloudflareSpeed, LibreSpeed, NetflixFast, OoklaSpeedtest})
+	assert.True(t, sort.SliceIsSorted(tools, func(i, j int) bool {
+		return tools[i] < tools[j]
+	}), tools)
+}
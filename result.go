@@ -0,0 +1,295 @@
+package speedtest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/measurement"
+)
+
+// Result represents results of a full speedtest run
+type Result struct {
+	// Tool is a name of measurement tool used for run
+	Tool string
+
+	// Timestamp is a time when run started
+	Timestamp time.Time
+
+	// Duration is a time it took to finish all measurements
+	Duration time.Duration
+
+	// Phases are phases run for result, results of skipped
+	// phases are zero, empty means that all phases were run
+	Phases []Phase
+
+	Download BitRate
+	Upload   BitRate
+	Latency  time.Duration
+	Jitter   time.Duration
+
+	// DownloadSpread and UploadSpread are
+	// spreads of rates across servers
+	DownloadSpread Spread
+	UploadSpread   Spread
+
+	BytesReceived int64
+	BytesSent     int64
+
+	// Servers are servers used for measurements
+	Servers []Server
+
+	// DownloadServers and UploadServers are per server
+	// results, they are set only if measurer implements
+	// TransferMeasurer interface
+	DownloadServers []ServerTransfer
+	UploadServers   []ServerTransfer
+
+	// ClientIP and ISP are set only if
+	// measurement tool provides them
+	ClientIP string
+	ISP      string
+
+	// IPVersion is an address family used for measurement,
+	// either 4 or 6, it's zero if it's unknown
+	IPVersion int
+}
+
+// TransferMeasurer is implemented by measurers that are able to
+// report details of measurement, such as per server rates, amount
+// of transferred bytes and errors of failed servers.
+//
+// Both built-in measurement tools implement it, so Measurer
+// returned by New can be asserted to this interface
+type TransferMeasurer interface {
+	// MeasureDownloadTransfer measures download speed per second
+	// and returns details of measurement
+	MeasureDownloadTransfer(ctx context.Context) (
+		transfer Transfer,
+		err error,
+	)
+
+	// MeasureUploadTransfer measures upload speed per second
+	// and returns details of measurement
+	MeasureUploadTransfer(ctx context.Context) (
+		transfer Transfer,
+		err error,
+	)
+}
+
+// ServerLister is implemented by measurers that are able to
+// list servers, which would be used for measurement
+type ServerLister interface {
+	// Servers returns servers used for measurement
+	Servers(ctx context.Context) ([]Server, error)
+}
+
+// clientInfoProvider is implemented by measurers
+// that are able to report details about client
+type clientInfoProvider interface {
+	ClientInfo(ctx context.Context) (measurement.ClientInfo, error)
+}
+
+// Run runs latency, download and upload measurements using provided
+// tool and returns results of them, phases can be limited with WithPhases
+func Run(ctx context.Context, tool Tool, opts ...config.Option) (*Result, error) {
+	measurer, err := New(tool, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Measure(ctx, measurer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result.Tool = tool.String()
+
+	return result, nil
+}
+
+// Measure runs latency, download and upload measurements using provided
+// measurer and returns results of them, phases can be limited with
+// WithPhases, other options are ignored, since measurer is already
+// configured. Tool of result is left empty. In case of error, per
+// server results measured so far are returned as well
+func Measure(ctx context.Context, measurer Measurer, opts ...config.Option) (*Result, error) {
+	conf := &config.Config{}
+	for _, opt := range opts {
+		opt(conf)
+	}
+
+	result := &Result{
+		Timestamp: time.Now(),
+	}
+
+	for _, phase := range []Phase{PhaseLatency, PhaseDownload, PhaseUpload} {
+		if conf.RunsPhase(phase) {
+			result.Phases = append(result.Phases, phase)
+		}
+	}
+
+	if conf.RunsPhase(PhaseLatency) {
+		latency, err := measurer.MeasureLatency(ctx)
+		if err != nil {
+			return result, err
+		}
+		result.Latency = latency.Avg
+		result.Jitter = latency.Jitter
+	}
+
+	if conf.RunsPhase(PhaseDownload) {
+		download, err := measureDownload(ctx, measurer)
+		result.DownloadServers = download.Servers
+		if err != nil {
+			return result, err
+		}
+		result.Download = download.Rate
+		result.DownloadSpread = download.Spread
+		result.BytesReceived = download.Bytes
+		result.addServers(download.Servers)
+	}
+
+	if conf.RunsPhase(PhaseUpload) {
+		upload, err := measureUpload(ctx, measurer)
+		result.UploadServers = upload.Servers
+		if err != nil {
+			return result, err
+		}
+		result.Upload = upload.Rate
+		result.UploadSpread = upload.Spread
+		result.BytesSent = upload.Bytes
+		result.addServers(upload.Servers)
+	}
+
+	// client details are optional, so
+	// failure to get them isn't fatal
+	if provider, ok := measurer.(clientInfoProvider); ok {
+		if info, err := provider.ClientInfo(ctx); err == nil {
+			result.ClientIP = info.IP
+			result.ISP = info.ISP
+		}
+	}
+
+	result.IPVersion = conf.IPVersion
+	if result.IPVersion == IPAuto {
+		result.IPVersion = ipVersionOf(result.ClientIP)
+	}
+
+	result.Duration = time.Since(result.Timestamp)
+
+	return result, nil
+}
+
+// Ran reports whether provided phase was run for result
+func (r *Result) Ran(phase Phase) bool {
+	if len(r.Phases) == 0 {
+		return true
+	}
+
+	for _, p := range r.Phases {
+		if p == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON implements json.Marshaler, results
+// of skipped phases are represented as null
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+
+	out := struct {
+		result
+
+		Download        *BitRate
+		Upload          *BitRate
+		Latency         *time.Duration
+		Jitter          *time.Duration
+		DownloadSpread  *Spread
+		UploadSpread    *Spread
+		BytesReceived   *int64
+		BytesSent       *int64
+		DownloadServers []ServerTransfer
+		UploadServers   []ServerTransfer
+	}{
+		result: result(r),
+	}
+
+	if r.Ran(PhaseLatency) {
+		out.Latency = &r.Latency
+		out.Jitter = &r.Jitter
+	}
+	if r.Ran(PhaseDownload) {
+		out.Download = &r.Download
+		out.DownloadSpread = &r.DownloadSpread
+		out.BytesReceived = &r.BytesReceived
+		out.DownloadServers = r.DownloadServers
+	}
+	if r.Ran(PhaseUpload) {
+		out.Upload = &r.Upload
+		out.UploadSpread = &r.UploadSpread
+		out.BytesSent = &r.BytesSent
+		out.UploadServers = r.UploadServers
+	}
+
+	return json.Marshal(out)
+}
+
+// measureDownload measures download using the most detailed
+// method available for measurer
+func measureDownload(ctx context.Context, measurer Measurer) (Transfer, error) {
+	if m, ok := measurer.(TransferMeasurer); ok {
+		return m.MeasureDownloadTransfer(ctx)
+	}
+
+	rate, err := measurer.MeasureDownload(ctx)
+	return Transfer{Rate: rate}, err
+}
+
+// measureUpload measures upload using the most detailed
+// method available for measurer
+func measureUpload(ctx context.Context, measurer Measurer) (Transfer, error) {
+	if m, ok := measurer.(TransferMeasurer); ok {
+		return m.MeasureUploadTransfer(ctx)
+	}
+
+	rate, err := measurer.MeasureUpload(ctx)
+	return Transfer{Rate: rate}, err
+}
+
+// addServers adds servers to result skipping already added ones
+func (r *Result) addServers(transfers []ServerTransfer) {
+	for _, transfer := range transfers {
+		server := transfer.Server
+
+		exists := false
+		for _, s := range r.Servers {
+			if s.URL == server.URL {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			r.Servers = append(r.Servers, server)
+		}
+	}
+}
+
+// ipVersionOf returns version of ip as seen by measurement
+// tool, zero is returned if ip is unknown
+func ipVersionOf(ip string) int {
+	parsed := net.ParseIP(ip)
+	switch {
+	case parsed == nil:
+		return IPAuto
+	case parsed.To4() != nil:
+		return IPv4
+	}
+
+	return IPv6
+}
@@ -0,0 +1,67 @@
+package speedtest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/internal/measurement"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPVersionOf(t *testing.T) {
+	tableTests := map[string]struct {
+		ip              string
+		expectedVersion int
+	}{
+		"success-ipv4": {
+			ip:              "192.0.2.1",
+			expectedVersion: IPv4,
+		},
+		"success-ipv6": {
+			ip:              "2001:db8::1",
+			expectedVersion: IPv6,
+		},
+		"success-unknown": {
+			ip:              "",
+			expectedVersion: IPAuto,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedVersion, ipVersionOf(testCase.ip))
+		})
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	result := &Result{
+		Tool:      "ookla",
+		Timestamp: time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC),
+		Phases:    []measurement.Phase{PhaseLatency, PhaseUpload},
+		Upload:    10_500_000,
+		Latency:   12 * time.Millisecond,
+		Jitter:    time.Millisecond,
+		BytesSent: 500,
+	}
+
+	data, err := json.Marshal(result)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Download":null`)
+	assert.Contains(t, string(data), `"BytesReceived":null`)
+	assert.Contains(t, string(data), `"Upload":10500000`)
+
+	decoded := &Result{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, result, decoded)
+	assert.False(t, decoded.Ran(PhaseDownload))
+	assert.True(t, decoded.Ran(PhaseUpload))
+}
+
+func TestResultRan(t *testing.T) {
+	assert.True(t, (&Result{}).Ran(PhaseDownload))
+	assert.False(t, (&Result{Phases: []measurement.Phase{PhaseUpload}}).Ran(PhaseDownload))
+}
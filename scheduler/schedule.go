@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule determines when runs are started
+type Schedule interface {
+	// Next returns time of the next run after t
+	Next(t time.Time) time.Time
+}
+
+// interval is a schedule of runs started every fixed interval
+type interval time.Duration
+
+// Every returns schedule of runs started every d,
+// d shorter than a second is rounded up to a second
+func Every(d time.Duration) Schedule {
+	if d < time.Second {
+		d = time.Second
+	}
+
+	return interval(d)
+}
+
+// Next returns time of the next run after t
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+// cron is a schedule defined by cron expression, each field is
+// a bit set of allowed values, e.g. bit 5 of minute means 5th minute
+type cron struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// domStar and dowStar are set if day fields are wildcards,
+	// if both day fields are restricted, then either may match
+	domStar bool
+	dowStar bool
+}
+
+// cronField describes range of values of cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteField = cronField{"minute", 0, 59}
+	hourField   = cronField{"hour", 0, 23}
+	domField    = cronField{"day of month", 1, 31}
+	monthField  = cronField{"month", 1, 12}
+	dowField    = cronField{"day of week", 0, 7}
+)
+
+// cronMacros are shortcuts for common cron expressions
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses standard 5 field cron expression: minute, hour, day of
+// month, month and day of week. Fields support wildcards, lists, ranges
+// and steps, e.g. "*/15 8-18 * * 1-5". Macros such as "@hourly" and
+// "@daily" are supported as well. Times are matched in their location
+func ParseCron(expr string) (Schedule, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("failed to parse cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	c := &cron{
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+
+	var err error
+	for i, f := range []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{minuteField, &c.minute},
+		{hourField, &c.hour},
+		{domField, &c.dom},
+		{monthField, &c.month},
+		{dowField, &c.dow},
+	} {
+		*f.bits, err = parseCronField(fields[i], f.field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cron expression %q: %w", expr, err)
+		}
+	}
+
+	// 7 is an alias of sunday in day of week field
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+
+	if _, err := c.next(time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to parse cron expression %q: %w", expr, err)
+	}
+
+	return c, nil
+}
+
+// parseCronField parses comma separated list of cron field values
+func parseCronField(s string, field cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(s, ",") {
+		rangeExpr, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeExpr = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q of %s", part[i+1:], field.name)
+			}
+		}
+
+		low, high := field.min, field.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+
+			var err error
+			if low, err = parseCronValue(bounds[0], field); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(bounds[1], field); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q of %s", rangeExpr, field.name)
+			}
+		default:
+			var err error
+			if low, err = parseCronValue(rangeExpr, field); err != nil {
+				return 0, err
+			}
+
+			// single value with step means range up to maximum, e.g. "5/15"
+			high = low
+			if step > 1 {
+				high = field.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses single value of cron field
+func parseCronValue(s string, field cronField) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q of %s", s, field.name)
+	}
+
+	if v < field.min || v > field.max {
+		return 0, fmt.Errorf("value %d of %s is out of range [%d, %d]", v, field.name, field.min, field.max)
+	}
+
+	return v, nil
+}
+
+// errNoCronMatch is returned if cron expression never matches, e.g. "0 0 31 2 *"
+var errNoCronMatch = errors.New("cron expression doesn't match any time")
+
+// cronSearchLimit limits search of the next run, since some
+// expressions never match, e.g. 31st of February
+const cronSearchLimit = 5
+
+// Next returns time of the next run after t, zero
+// time is returned if expression never matches
+func (c *cron) Next(t time.Time) time.Time {
+	next, err := c.next(t)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return next
+}
+
+// next searches the next matching minute after t, moving to the start
+// of the next month, day, hour or minute whenever field doesn't match
+func (c *cron) next(t time.Time) (time.Time, error) {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + cronSearchLimit
+
+	for t.Year() <= yearLimit {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+
+		return t, nil
+	}
+
+	return time.Time{}, errNoCronMatch
+}
+
+// dayMatches checks whether day of t matches day fields
+func (c *cron) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvery(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(time.Hour), Every(time.Hour).Next(now))
+	assert.Equal(t, now.Add(time.Second), Every(time.Millisecond).Next(now))
+}
+
+func TestParseCron(t *testing.T) {
+	// 2022-08-01 is monday
+	now := time.Date(2022, 8, 1, 12, 7, 30, 0, time.UTC)
+
+	tableTests := map[string]struct {
+		expr         string
+		expectedNext time.Time
+		expectedErr  string
+	}{
+		"success-every-minute": {
+			expr:         "* * * * *",
+			expectedNext: time.Date(2022, 8, 1, 12, 8, 0, 0, time.UTC),
+		},
+		"success-step": {
+			expr:         "*/15 * * * *",
+			expectedNext: time.Date(2022, 8, 1, 12, 15, 0, 0, time.UTC),
+		},
+		"success-offset-step": {
+			expr:         "5/10 * * * *",
+			expectedNext: time.Date(2022, 8, 1, 12, 15, 0, 0, time.UTC),
+		},
+		"success-list-and-range": {
+			expr:         "0,30 8-11 * * *",
+			expectedNext: time.Date(2022, 8, 2, 8, 0, 0, 0, time.UTC),
+		},
+		"success-weekday": {
+			expr:         "0 9 * * 6-7",
+			expectedNext: time.Date(2022, 8, 6, 9, 0, 0, 0, time.UTC),
+		},
+		"success-day-of-month-or-week": {
+			expr:         "0 0 15 * 3",
+			expectedNext: time.Date(2022, 8, 3, 0, 0, 0, 0, time.UTC),
+		},
+		"success-next-year": {
+			expr:         "0 0 1 1 *",
+			expectedNext: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"success-macro": {
+			expr:         "@daily",
+			expectedNext: time.Date(2022, 8, 2, 0, 0, 0, 0, time.UTC),
+		},
+		"error-fields-count": {
+			expr:        "* * * *",
+			expectedErr: `failed to parse cron expression "* * * *": expected 5 fields, got 4`,
+		},
+		"error-out-of-range": {
+			expr:        "60 * * * *",
+			expectedErr: `failed to parse cron expression "60 * * * *": value 60 of minute is out of range [0, 59]`,
+		},
+		"error-invalid-step": {
+			expr:        "*/0 * * * *",
+			expectedErr: `failed to parse cron expression "*/0 * * * *": invalid step "0" of minute`,
+		},
+		"error-invalid-range": {
+			expr:        "* 10-5 * * *",
+			expectedErr: `failed to parse cron expression "* 10-5 * * *": invalid range "10-5" of hour`,
+		},
+		"error-never-matches": {
+			expr:        "0 0 31 2 *",
+			expectedErr: `failed to parse cron expression "0 0 31 2 *": cron expression doesn't match any time`,
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			schedule, err := ParseCron(testCase.expr)
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedNext, schedule.Next(now))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package scheduler runs speedtest measurements periodically
+// and stores their results in history store
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/bejaneps/speedtest/history"
+	"github.com/bejaneps/speedtest/internal/config"
+	"github.com/bejaneps/speedtest/internal/pkg/random"
+)
+
+const defaultTimeout = 2 * time.Minute
+
+// defaultJitterFunc is a variable to wrap random.Duration
+// function for deterministic results
+var defaultJitterFunc = random.Duration
+
+// Option configures Scheduler
+type Option func(*Scheduler)
+
+// WithJitter delays each run by random duration up to jitter,
+// so runs of multiple hosts with the same schedule are spread
+func WithJitter(jitter time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jitter = jitter
+	}
+}
+
+// WithTimeout sets timeout of a single run, default timeout is 2 minutes
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.timeout = timeout
+	}
+}
+
+// WithMeasureOptions sets options passed to speedtest.Measure,
+// e.g. speedtest.WithPhases to limit measured phases
+func WithMeasureOptions(opts ...config.Option) Option {
+	return func(s *Scheduler) {
+		s.measureOpts = opts
+	}
+}
+
+// WithErrorHandler sets function called when run fails or its
+// result can't be stored, errors are printed by default
+func WithErrorHandler(fn func(error)) Option {
+	return func(s *Scheduler) {
+		s.onError = fn
+	}
+}
+
+// Scheduler runs measurements on schedule and stores their results
+type Scheduler struct {
+	tool     string
+	measurer speedtest.Measurer
+	schedule Schedule
+	store    history.Store
+
+	jitter      time.Duration
+	timeout     time.Duration
+	measureOpts []config.Option
+	onError     func(error)
+}
+
+// New creates scheduler running measurements with measurer on schedule,
+// tool is a name of measurement tool recorded in results
+func New(
+	tool string,
+	measurer speedtest.Measurer,
+	schedule Schedule,
+	store history.Store,
+	opts ...Option,
+) *Scheduler {
+	s := &Scheduler{
+		tool:     tool,
+		measurer: measurer,
+		schedule: schedule,
+		store:    store,
+		timeout:  defaultTimeout,
+		onError: func(err error) {
+			fmt.Printf("scheduled run failed: %v\n", err)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Run runs measurements on schedule until ctx is done, failed runs
+// are reported to error handler and don't stop scheduling. Runs are
+// scheduled after previous scheduled time rather than end of previous
+// run, so schedule doesn't drift, runs missed while measuring are skipped
+func (s *Scheduler) Run(ctx context.Context) error {
+	next := s.schedule.Next(time.Now())
+	for {
+		if next.IsZero() {
+			return errors.New("schedule has no next run")
+		}
+
+		timer := time.NewTimer(time.Until(next) + defaultJitterFunc(s.jitter))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := s.RunOnce(ctx); err != nil {
+			s.onError(err)
+		}
+
+		now := time.Now()
+		next = s.schedule.Next(next)
+		for !next.IsZero() && !next.After(now) {
+			next = s.schedule.Next(next)
+		}
+	}
+}
+
+// RunOnce runs measurement immediately and stores its result
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	result, err := speedtest.Measure(ctx, s.measurer, s.measureOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to measure: %w", err)
+	}
+	result.Tool = s.tool
+
+	if err := s.store.Add(result); err != nil {
+		return fmt.Errorf("failed to store result: %w", err)
+	}
+
+	return nil
+}
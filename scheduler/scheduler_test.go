@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bejaneps/speedtest/history"
+	"github.com/bejaneps/speedtest/internal/measurement"
+	"github.com/bejaneps/speedtest/internal/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubMeasurer returns preset results of measurements,
+// starts of latency measurements are recorded
+type stubMeasurer struct {
+	rate  measurement.BitRate
+	err   error
+	delay time.Duration
+
+	mu     sync.Mutex
+	starts []time.Time
+}
+
+func (m *stubMeasurer) MeasureDownload(ctx context.Context) (measurement.BitRate, error) {
+	return m.rate, m.err
+}
+
+func (m *stubMeasurer) MeasureUpload(ctx context.Context) (measurement.BitRate, error) {
+	return m.rate, m.err
+}
+
+func (m *stubMeasurer) MeasureLatency(ctx context.Context) (measurement.Latency, error) {
+	m.mu.Lock()
+	m.starts = append(m.starts, time.Now())
+	m.mu.Unlock()
+
+	time.Sleep(m.delay)
+	return measurement.Latency{}, m.err
+}
+
+// stubSchedule schedules runs every interval
+// without rounding, so tests are fast
+type stubSchedule time.Duration
+
+func (s stubSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+func TestRunOnce(t *testing.T) {
+	tableTests := map[string]struct {
+		measurer    *stubMeasurer
+		expectedLen int
+		expectedErr string
+	}{
+		"success": {
+			measurer:    &stubMeasurer{rate: 1000},
+			expectedLen: 1,
+		},
+		"error-measure": {
+			measurer:    &stubMeasurer{err: errors.New("random error")},
+			expectedErr: "failed to measure: random error",
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			store := history.NewRing(10)
+			s := New("ookla", testCase.measurer, Every(time.Hour), store)
+
+			err := s.RunOnce(context.Background())
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			results, err := store.Results()
+			assert.NoError(t, err)
+			assert.Len(t, results, testCase.expectedLen)
+			for _, result := range results {
+				assert.Equal(t, "ookla", result.Tool)
+				assert.Equal(t, testCase.measurer.rate, result.Download)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		jitters []time.Duration
+	)
+	defaultJitterFunc = func(max time.Duration) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+
+		jitters = append(jitters, max)
+		return time.Millisecond
+	}
+	t.Cleanup(func() {
+		defaultJitterFunc = random.Duration
+	})
+
+	var errs []error
+	store := history.NewRing(10)
+	s := New(
+		"netflix",
+		&stubMeasurer{err: errors.New("random error")},
+		stubSchedule(10*time.Millisecond),
+		store,
+		WithJitter(5*time.Millisecond),
+		WithErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := s.Run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// failed runs don't stop scheduling
+	assert.GreaterOrEqual(t, len(errs), 2)
+	for _, err := range errs {
+		assert.EqualError(t, err, "failed to measure: random error")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, jitter := range jitters {
+		assert.Equal(t, 5*time.Millisecond, jitter)
+	}
+}
+
+func TestRunDoesNotDrift(t *testing.T) {
+	defaultJitterFunc = func(max time.Duration) time.Duration {
+		return 0
+	}
+	t.Cleanup(func() {
+		defaultJitterFunc = random.Duration
+	})
+
+	// each run takes most of interval, so
+	// schedule would drift if runs were
+	// scheduled after end of previous run
+	measurer := &stubMeasurer{rate: 1000, delay: 30 * time.Millisecond}
+	s := New("netflix", measurer, stubSchedule(50*time.Millisecond), history.NewRing(10))
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 230*time.Millisecond)
+	defer cancel()
+
+	err := s.Run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	measurer.mu.Lock()
+	defer measurer.mu.Unlock()
+
+	assert.Len(t, measurer.starts, 4)
+	for i, runStart := range measurer.starts {
+		expected := time.Duration(i+1) * 50 * time.Millisecond
+		assert.InDelta(t, expected, runStart.Sub(start), float64(15*time.Millisecond), i)
+	}
+}
+
+func TestRunSkipsMissedRuns(t *testing.T) {
+	defaultJitterFunc = func(max time.Duration) time.Duration {
+		return 0
+	}
+	t.Cleanup(func() {
+		defaultJitterFunc = random.Duration
+	})
+
+	// run takes longer than interval, so the next
+	// scheduled time passes while measuring
+	measurer := &stubMeasurer{rate: 1000, delay: 70 * time.Millisecond}
+	s := New("netflix", measurer, stubSchedule(50*time.Millisecond), history.NewRing(10))
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Millisecond)
+	defer cancel()
+
+	err := s.Run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	measurer.mu.Lock()
+	defer measurer.mu.Unlock()
+
+	assert.Len(t, measurer.starts, 2)
+	assert.InDelta(t, 150*time.Millisecond, measurer.starts[1].Sub(start), float64(15*time.Millisecond))
+}
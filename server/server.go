@@ -0,0 +1,229 @@
+// Package server serves HTTP endpoints of Ookla's speedtest.net
+// servers, so speed can be measured against self-hosted servers
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxDimension limits dimension of served images,
+	// it's the largest dimension requested by clients
+	maxDimension = 4000
+
+	// randomDataSize is a size of random data that is
+	// repeated to make image of requested dimension
+	randomDataSize = 1 << 20
+
+	uploadPath    = "/upload.php"
+	latencyPath   = "/latency.txt"
+	serversPath   = "/api/js/servers"
+	randomPrefix  = "/random"
+	randomSuffix  = ".jpg"
+	latencyAnswer = "test=test\n"
+)
+
+// Entry is an entry of server list served at "/api/js/servers",
+// URL should point to "/upload.php" endpoint of server
+type Entry struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Sponsor  string  `json:"sponsor"`
+	Country  string  `json:"country"`
+	Lat      float64 `json:"lat,string"`
+	Lon      float64 `json:"lon,string"`
+	Distance float64 `json:"distance"`
+	Host     string  `json:"host"`
+	URL      string  `json:"url"`
+}
+
+// Option configures Server
+type Option func(*Server)
+
+// WithEntries sets entries of server list, by default
+// list contains only server itself, with URL derived
+// from host of request
+func WithEntries(entries ...Entry) Option {
+	return func(s *Server) {
+		s.entries = entries
+	}
+}
+
+// WithName sets name, sponsor and country of server
+// in default server list
+func WithName(name, sponsor, country string) Option {
+	return func(s *Server) {
+		s.self.Name = name
+		s.self.Sponsor = sponsor
+		s.self.Country = country
+	}
+}
+
+// Server serves endpoints used by speedtest.net clients:
+// "/random{W}x{H}.jpg" for download, "/upload.php" for upload,
+// "/latency.txt" for latency and "/api/js/servers" for server
+// list, it implements http.Handler
+type Server struct {
+	self    Entry
+	entries []Entry
+	data    []byte
+}
+
+// New creates server
+func New(opts ...Option) *Server {
+	s := &Server{
+		self: Entry{
+			ID:   "1",
+			Name: "self-hosted",
+		},
+		data: make([]byte, randomDataSize),
+	}
+
+	// image content doesn't matter, it's random
+	// only to make it incompressible
+	rand.New(rand.NewSource(1)).Read(s.data)
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+
+	switch {
+	case path == uploadPath:
+		s.upload(w, r)
+	case path == latencyPath:
+		s.latency(w, r)
+	case path == serversPath:
+		s.servers(w, r)
+	case strings.HasPrefix(path, randomPrefix) && strings.HasSuffix(path, randomSuffix):
+		s.download(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// download writes random content of image with requested dimensions,
+// size of image is the same as of images served by speedtest.net
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	width, height, ok := parseDimensions(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	size := int64(width) * int64(height) * 2
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	for size > 0 {
+		chunk := s.data
+		if int64(len(chunk)) > size {
+			chunk = chunk[:size]
+		}
+
+		n, err := w.Write(chunk)
+		if err != nil {
+			// client went away, nothing to report
+			return
+		}
+		size -= int64(n)
+	}
+}
+
+// upload reads and discards request body
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n, err := io.Copy(io.Discard, r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintf(w, "size=%d", n)
+}
+
+// latency writes tiny constant response
+func (s *Server) latency(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Cache-Control", "no-store")
+	io.WriteString(w, latencyAnswer)
+}
+
+// servers writes server list limited by "limit" query parameter
+func (s *Server) servers(w http.ResponseWriter, r *http.Request) {
+	entries := s.entries
+	if len(entries) == 0 {
+		self := s.self
+		self.Host = r.Host
+		self.URL = requestScheme(r) + "://" + r.Host + uploadPath
+		entries = []Entry{self}
+	}
+
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		fmt.Printf("failed to write server list: %v\n", err)
+	}
+}
+
+// parseDimensions parses width and height from path like "/random1000x1000.jpg"
+func parseDimensions(path string) (width, height int, ok bool) {
+	dimensions := strings.TrimSuffix(strings.TrimPrefix(path, randomPrefix), randomSuffix)
+
+	w, h, found := strings.Cut(dimensions, "x")
+	if !found {
+		return 0, 0, false
+	}
+
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if errW != nil || errH != nil ||
+		width < 1 || width > maxDimension ||
+		height < 1 || height > maxDimension {
+		return 0, 0, false
+	}
+
+	return width, height, true
+}
+
+// requestScheme returns scheme of request as seen by client
+func requestScheme(r *http.Request) string {
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return proto
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeHTTP(t *testing.T) {
+	tableTests := map[string]struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedLen    int
+	}{
+		"success-download": {
+			method:         http.MethodGet,
+			path:           "/random350x350.jpg",
+			expectedStatus: http.StatusOK,
+			expectedLen:    350 * 350 * 2,
+		},
+		"success-download-larger-than-random-data": {
+			method:         http.MethodGet,
+			path:           "/random1000x1000.jpg",
+			expectedStatus: http.StatusOK,
+			expectedLen:    1000 * 1000 * 2,
+		},
+		"success-upload": {
+			method:         http.MethodPost,
+			path:           "/upload.php",
+			body:           "content=aBcD",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "size=12",
+		},
+		"success-latency": {
+			method:         http.MethodGet,
+			path:           "/latency.txt",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "test=test\n",
+		},
+		"error-download-too-large": {
+			method:         http.MethodGet,
+			path:           "/random5000x5000.jpg",
+			expectedStatus: http.StatusNotFound,
+		},
+		"error-download-invalid-dimensions": {
+			method:         http.MethodGet,
+			path:           "/random1000.jpg",
+			expectedStatus: http.StatusNotFound,
+		},
+		"error-upload-method": {
+			method:         http.MethodGet,
+			path:           "/upload.php",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		"error-unknown-path": {
+			method:         http.MethodGet,
+			path:           "/speedtest-config.php",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	s := New()
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.path, strings.NewReader(testCase.body))
+
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, testCase.expectedStatus, rec.Code)
+			if testCase.expectedBody != "" {
+				assert.Equal(t, testCase.expectedBody, rec.Body.String())
+			}
+			if testCase.expectedLen != 0 {
+				assert.Equal(t, testCase.expectedLen, rec.Body.Len())
+			}
+		})
+	}
+}
+
+func TestServers(t *testing.T) {
+	tableTests := map[string]struct {
+		server          *Server
+		query           string
+		expectedEntries []Entry
+	}{
+		"success-self": {
+			server: New(WithName("Office", "Example", "Netherlands")),
+			expectedEntries: []Entry{
+				{
+					ID:      "1",
+					Name:    "Office",
+					Sponsor: "Example",
+					Country: "Netherlands",
+					Host:    "speedtest.example.com",
+					URL:     "http://speedtest.example.com/upload.php",
+				},
+			},
+		},
+		"success-entries-limited": {
+			server: New(WithEntries(
+				Entry{ID: "1", URL: "http://a.example.com/upload.php"},
+				Entry{ID: "2", URL: "http://b.example.com/upload.php"},
+			)),
+			query: "?engine=js&limit=1",
+			expectedEntries: []Entry{
+				{ID: "1", URL: "http://a.example.com/upload.php"},
+			},
+		},
+	}
+
+	for testName, testCase := range tableTests {
+		testCase := testCase
+		t.Run(testName, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://speedtest.example.com/api/js/servers"+testCase.query, nil)
+
+			testCase.server.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var entries []Entry
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&entries))
+			assert.Equal(t, testCase.expectedEntries, entries)
+		})
+	}
+}
+
+func TestDownloadIncompressible(t *testing.T) {
+	srv := httptest.NewServer(New())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/random500x500.jpg")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(body)), resp.ContentLength)
+	assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+
+	// content isn't trivially compressible
+	zeros := 0
+	for _, b := range body[:1000] {
+		if b == 0 {
+			zeros++
+		}
+	}
+	assert.Less(t, zeros, 50)
+}
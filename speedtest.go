@@ -2,28 +2,126 @@ package speedtest
 
 import (
 	"context"
-	"net/http"
+	"crypto/tls"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/bejaneps/speedtest/internal/config"
 	"github.com/bejaneps/speedtest/internal/measurement"
-	"github.com/bejaneps/speedtest/internal/netflix"
-	"github.com/bejaneps/speedtest/internal/ookla"
 )
 
 const reqTimeoutDuration = 60 * time.Second
 
-type measurementTool int
+// Tool is a name of measurement tool, measurer of tool is built
+// by factory registered with Register, built-in tools are
+// registered by default
+type Tool string
 
 const (
 	// OoklaSpeedtest is Ookla's speedtest.net tool
-	OoklaSpeedtest measurementTool = iota
+	OoklaSpeedtest Tool = "ookla"
 
 	// NetflixFast is Netflix's fast.com tool
-	NetflixFast
+	NetflixFast Tool = "netflix"
+
+	// LibreSpeed is open source LibreSpeed tool, it uses
+	// public servers or self-hosted instances
+	LibreSpeed Tool = "librespeed"
+
+	// CloudflareSpeed is Cloudflare's speed.cloudflare.com tool
+	CloudflareSpeed Tool = "cloudflare"
+)
+
+// String returns name of measurement tool
+func (t Tool) String() string {
+	return string(t)
+}
+
+const (
+	// PhaseLatency is a phase of latency measurement
+	PhaseLatency = measurement.PhaseLatency
+
+	// PhaseDownload is a phase of download measurement
+	PhaseDownload = measurement.PhaseDownload
+
+	// PhaseUpload is a phase of upload measurement
+	PhaseUpload = measurement.PhaseUpload
+)
+
+const (
+	// Bits formats bit rate in bits per second
+	Bits = measurement.Bits
+
+	// Bytes formats bit rate in bytes per second
+	Bytes = measurement.Bytes
+
+	// DecimalPrefix scales bit rate by powers of 1000
+	DecimalPrefix = measurement.DecimalPrefix
+
+	// BinaryPrefix scales bit rate by powers of 1024
+	BinaryPrefix = measurement.BinaryPrefix
+)
+
+const (
+	// IPAuto lets dialer pick address family
+	IPAuto = 0
+
+	// IPv4 restricts connections to IPv4
+	IPv4 = 4
+
+	// IPv6 restricts connections to IPv6
+	IPv6 = 6
 )
 
-// Measurer is an interface for measuring download/upload speeds
+// Kbps returns bit rate of v Kilobits per second
+func Kbps(v float64) measurement.BitRate {
+	return measurement.Kbps(v)
+}
+
+// Mbps returns bit rate of v Megabits per second
+func Mbps(v float64) measurement.BitRate {
+	return measurement.Mbps(v)
+}
+
+// Gbps returns bit rate of v Gigabits per second
+func Gbps(v float64) measurement.BitRate {
+	return measurement.Gbps(v)
+}
+
+// Mean returns arithmetic mean of rates
+func Mean(rates []measurement.BitRate) measurement.BitRate {
+	return measurement.Mean(rates)
+}
+
+// Median returns median of rates
+func Median(rates []measurement.BitRate) measurement.BitRate {
+	return measurement.Median(rates)
+}
+
+// Maximum returns the highest of rates, like fast.com does
+func Maximum(rates []measurement.BitRate) measurement.BitRate {
+	return measurement.Maximum(rates)
+}
+
+// TrimmedMean returns aggregation calculating arithmetic mean of rates
+// after dropping provided fraction of the lowest and of the highest ones
+func TrimmedMean(fraction float64) Aggregation {
+	return measurement.TrimmedMean(fraction)
+}
+
+// Percentile returns aggregation calculating p-th percentile of rates
+func Percentile(p float64) Aggregation {
+	return measurement.Percentile(p)
+}
+
+// ParseBitRate parses bit rate from string like "94.2 Mbps",
+// "1.2 Gbps" or "11.5 MiB/s"
+func ParseBitRate(s string) (measurement.BitRate, error) {
+	return measurement.ParseBitRate(s)
+}
+
+// Measurer is an interface for measuring download/upload speeds and latency
 type Measurer interface {
 	// MeasureDownload measures download speed per second
 	MeasureDownload(ctx context.Context) (
@@ -36,39 +134,34 @@ type Measurer interface {
 		uploadRate measurement.BitRate,
 		err error,
 	)
+
+	// MeasureLatency measures round trip time and jitter
+	MeasureLatency(ctx context.Context) (
+		latency measurement.Latency,
+		err error,
+	)
 }
 
-// New is a constructor for speedtest measure api
-func New(tool measurementTool, opts ...config.Option) (Measurer, error) {
+// New is a constructor for speedtest measure api, it
+// returns error if tool isn't registered
+func New(tool Tool, opts ...config.Option) (Measurer, error) {
+	factory, ok := lookup(tool)
+	if !ok {
+		return nil, fmt.Errorf("unknown measurement tool %q", tool)
+	}
+
 	conf := &config.Config{}
 
 	for _, opt := range opts {
 		opt(conf)
 	}
 
-	var (
-		measurer Measurer
-		err      error
-	)
-
-	switch tool {
-	case OoklaSpeedtest:
-		measurer = ookla.NewClient(
-			conf,
-			&http.Client{
-				Timeout: reqTimeoutDuration,
-			},
-		)
-	case NetflixFast:
-		measurer, err = netflix.NewClient(
-			conf,
-			&http.Client{
-				Timeout: reqTimeoutDuration,
-			},
-		)
+	doer, err := newHTTPDoer(conf)
+	if err != nil {
+		return nil, err
 	}
 
-	return measurer, err
+	return factory(conf, doer)
 }
 
 // WithServerCount sets limit on how many servers
@@ -82,10 +175,233 @@ func WithServerCount(serverCount int) config.Option {
 // WithToken sets authentication token for Netflix's
 // fast.com api.
 //
-// TODO: replace this option with a function
-// that will fetch token from response
+// It's optional, by default token is fetched from fast.com
+// and refreshed when it's rejected by api
 func WithToken(token string) config.Option {
 	return func(c *config.Config) {
 		c.Token = token
 	}
 }
+
+// WithServerListURL replaces URL from which list of servers is
+// requested, e.g. with URL of self-hosted server's "/api/js/servers"
+// endpoint or of LibreSpeed server list JSON, it's supported by
+// OoklaSpeedtest and LibreSpeed
+func WithServerListURL(url string) config.Option {
+	return func(c *config.Config) {
+		c.ServerListURL = url
+	}
+}
+
+// WithBaseURL replaces base URL of speed test endpoints,
+// e.g. with URL of local stand-in, it's supported only
+// by CloudflareSpeed
+func WithBaseURL(url string) config.Option {
+	return func(c *config.Config) {
+		c.BaseURL = url
+	}
+}
+
+// WithDuration sets target duration of download test against each
+// server, during the test size of requested content and amount of
+// concurrent requests are increased until the link is saturated.
+//
+// Currently it's supported by Ookla's speedtest.net, LibreSpeed
+// and Cloudflare's speed.cloudflare.com tools
+func WithDuration(duration time.Duration) config.Option {
+	return func(c *config.Config) {
+		c.TestDuration = duration
+	}
+}
+
+// WithWarmupDuration sets duration of warmup period at the beginning
+// of each transfer, data transferred during warmup period, while
+// TCP slow-start is in progress, is excluded from measured rate
+func WithWarmupDuration(duration time.Duration) config.Option {
+	return func(c *config.Config) {
+		c.WarmupDuration = duration
+	}
+}
+
+// WithWarmupBytes sets amount of bytes transferred during warmup
+// period at the beginning of each transfer, they are excluded
+// from measured rate
+func WithWarmupBytes(bytes int64) config.Option {
+	return func(c *config.Config) {
+		c.WarmupBytes = bytes
+	}
+}
+
+// WithSampleInterval makes transfer rate to be sampled every interval
+// after warmup period, reported rate is then an aggregation of samples,
+// so it reflects sustained transfer rather than bursts
+func WithSampleInterval(interval time.Duration) config.Option {
+	return func(c *config.Config) {
+		c.SampleInterval = interval
+	}
+}
+
+// WithServerAggregation sets aggregation combining rates of servers
+// into reported rate, by default arithmetic mean is used
+func WithServerAggregation(aggregation Aggregation) config.Option {
+	return func(c *config.Config) {
+		c.ServerAggregation = aggregation
+	}
+}
+
+// WithSampleAggregation sets aggregation combining rates sampled
+// during transfer against single server, it takes effect only
+// with WithSampleInterval, by default arithmetic mean is used
+func WithSampleAggregation(aggregation Aggregation) config.Option {
+	return func(c *config.Config) {
+		c.SampleAggregation = aggregation
+	}
+}
+
+// WithPhases limits phases of measurement run by Run,
+// by default all phases are run
+func WithPhases(phases ...Phase) config.Option {
+	return func(c *config.Config) {
+		c.Phases = phases
+	}
+}
+
+// WithFailFast makes measurement fail as soon as
+// any of servers fails, it's a default behaviour
+func WithFailFast() config.Option {
+	return func(c *config.Config) {
+		c.FailurePolicy = config.FailFast
+		c.MaxFailures = 0
+	}
+}
+
+// WithMaxFailures makes measurement tolerate up to maxFailures
+// failed servers, result is calculated from succeeded servers
+// and errors of failed ones are reported per server
+func WithMaxFailures(maxFailures int) config.Option {
+	return func(c *config.Config) {
+		c.FailurePolicy = config.TolerateFailures
+		c.MaxFailures = maxFailures
+	}
+}
+
+// WithBestEffort makes measurement succeed as long
+// as at least one of servers succeeded
+func WithBestEffort() config.Option {
+	return func(c *config.Config) {
+		c.FailurePolicy = config.BestEffort
+	}
+}
+
+// WithProgress sets callback that is called periodically during
+// measurement with its intermediate state, calls are serialized,
+// so callback doesn't need to be safe for concurrent use
+func WithProgress(fn func(Progress)) config.Option {
+	return func(c *config.Config) {
+		mu := sync.Mutex{}
+
+		c.Progress = func(p Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			fn(p)
+		}
+	}
+}
+
+// WithProgressChan sets channel that receives intermediate state
+// of measurement periodically, if channel isn't ready to receive,
+// then progress is dropped, so measurement is never blocked
+func WithProgressChan(ch chan<- Progress) config.Option {
+	return func(c *config.Config) {
+		c.Progress = func(p Progress) {
+			select {
+			case ch <- p:
+			default:
+			}
+		}
+	}
+}
+
+// WithProgressInterval sets how often progress is reported,
+// by default it's reported every 500 milliseconds
+func WithProgressInterval(interval time.Duration) config.Option {
+	return func(c *config.Config) {
+		c.ProgressInterval = interval
+	}
+}
+
+// WithHTTPClient sets client sending requests of measurement,
+// it can't be combined with transport options, such as
+// WithProxy, WithSourceIP, WithInterface and WithTLSConfig,
+// nor with WithRequestTimeout, timeout of client is used instead
+func WithHTTPClient(doer HTTPDoer) config.Option {
+	return func(c *config.Config) {
+		c.HTTPClient = doer
+	}
+}
+
+// WithProxy sends requests of measurement through proxy,
+// e.g. "http://proxy.example.com:3128" or "socks5://127.0.0.1:1080"
+func WithProxy(proxyURL string) config.Option {
+	return func(c *config.Config) {
+		c.Proxy = proxyURL
+	}
+}
+
+// WithSourceIP binds connections of measurement to local ip, so
+// each uplink of multi-homed host can be measured separately
+func WithSourceIP(ip string) config.Option {
+	return func(c *config.Config) {
+		c.SourceIP = ip
+	}
+}
+
+// WithInterface binds connections of measurement to the first
+// usable address of network interface, e.g. "eth1",
+// it's ignored if WithSourceIP is set
+func WithInterface(name string) config.Option {
+	return func(c *config.Config) {
+		c.Interface = name
+	}
+}
+
+// WithTLSConfig sets TLS config of connections of measurement
+func WithTLSConfig(tlsConfig *tls.Config) config.Option {
+	return func(c *config.Config) {
+		c.TLSConfig = tlsConfig
+	}
+}
+
+// WithUserAgent sets user agent of requests of measurement
+func WithUserAgent(userAgent string) config.Option {
+	return func(c *config.Config) {
+		c.UserAgent = userAgent
+	}
+}
+
+// WithRequestTimeout limits duration of a single request,
+// including reading its response, default is 60 seconds.
+// It can't be combined with WithHTTPClient
+func WithRequestTimeout(timeout time.Duration) config.Option {
+	return func(c *config.Config) {
+		c.RequestTimeout = timeout
+	}
+}
+
+// WithTimeout limits duration of a whole measurement, such as
+// single call of MeasureDownload, by default it isn't limited
+func WithTimeout(timeout time.Duration) config.Option {
+	return func(c *config.Config) {
+		c.Timeout = timeout
+	}
+}
+
+// WithIPVersion restricts connections of measurement, both for server
+// discovery and transfers, to IPv4 or IPv6, by default address family
+// is picked by dialer. It can't be combined with WithHTTPClient
+func WithIPVersion(version int) config.Option {
+	return func(c *config.Config) {
+		c.IPVersion = version
+	}
+}
@@ -0,0 +1,85 @@
+package speedtest_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/bejaneps/speedtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// transferTool is a tool registered by caller outside
+// of module, which reports details of transfers
+const transferTool speedtest.Tool = "transfer"
+
+var registerTransferTool sync.Once
+
+var (
+	_ speedtest.TransferMeasurer = (*transferMeasurer)(nil)
+	_ speedtest.ServerLister     = (*transferMeasurer)(nil)
+)
+
+// transferMeasurer measures against single server
+type transferMeasurer struct{}
+
+var transferServer = speedtest.Server{
+	Name: "Amsterdam",
+	URL:  "https://example.com",
+}
+
+func (m *transferMeasurer) MeasureDownload(ctx context.Context) (speedtest.BitRate, error) {
+	transfer, err := m.MeasureDownloadTransfer(ctx)
+	return transfer.Rate, err
+}
+
+func (m *transferMeasurer) MeasureUpload(ctx context.Context) (speedtest.BitRate, error) {
+	transfer, err := m.MeasureUploadTransfer(ctx)
+	return transfer.Rate, err
+}
+
+func (m *transferMeasurer) MeasureLatency(ctx context.Context) (speedtest.Latency, error) {
+	return speedtest.Latency{}, nil
+}
+
+func (m *transferMeasurer) MeasureDownloadTransfer(ctx context.Context) (speedtest.Transfer, error) {
+	return speedtest.Transfer{
+		Rate:   speedtest.Mbps(100),
+		Bytes:  1000,
+		Spread: speedtest.Spread{Min: speedtest.Mbps(100), Max: speedtest.Mbps(100)},
+		Servers: []speedtest.ServerTransfer{
+			{Server: transferServer, Rate: speedtest.Mbps(100), Bytes: 1000},
+		},
+	}, nil
+}
+
+func (m *transferMeasurer) MeasureUploadTransfer(ctx context.Context) (speedtest.Transfer, error) {
+	return speedtest.Transfer{
+		Rate:  speedtest.Mbps(10),
+		Bytes: 500,
+		Servers: []speedtest.ServerTransfer{
+			{Server: transferServer, Rate: speedtest.Mbps(10), Bytes: 500},
+		},
+	}, nil
+}
+
+func (m *transferMeasurer) Servers(ctx context.Context) ([]speedtest.Server, error) {
+	return []speedtest.Server{transferServer}, nil
+}
+
+func TestTransferMeasurer(t *testing.T) {
+	registerTransferTool.Do(func() {
+		speedtest.Register(transferTool, func(conf *speedtest.Config, doer speedtest.HTTPDoer) (speedtest.Measurer, error) {
+			return &transferMeasurer{}, nil
+		})
+	})
+
+	result, err := speedtest.Run(context.Background(), transferTool)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), result.BytesReceived)
+	assert.Equal(t, int64(500), result.BytesSent)
+	assert.Equal(t, []speedtest.Server{transferServer}, result.Servers)
+	if assert.Len(t, result.DownloadServers, 1) {
+		assert.Equal(t, speedtest.Mbps(100), result.DownloadServers[0].Rate)
+	}
+}